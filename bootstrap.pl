@@ -8,18 +8,20 @@
 :-(op(1200, fx, [:-, ?-])).
 :-(op(1105, xfy, '|')).
 :-(op(1100, xfy, ;)).
-:-(op(1050, xfy, ->)).
+:-(op(1050, xfy, [->, *->])).
 :-(op(1000, xfy, ',')).
 :-(op(900, fy, \+)).
 :-(op(700, xfx, [=, \=])).
 :-(op(700, xfx, [==, \==, @<, @=<, @>, @>=])).
+:-(op(700, xfx, [=@=, \=@=])).
 :-(op(700, xfx, =..)).
 :-(op(700, xfx, [is, =:=, =\=, <, =<, >, >=])).
-:-(op(600, xfy, :)).
 :-(op(500, yfx, [+, -, /\, \/])).
 :-(op(400, yfx, [*, /, //, div, rem, mod, <<, >>])).
 :-(op(200, xfx, **)).
 :-(op(200, xfy, ^)).
+:-(op(200, xfy, :)).
+:-(op(200, xfx, @)).
 :-(op(200, fy, [+, -, \])).
 
 % Control constructs
@@ -34,15 +36,22 @@ P, Q :- call((P, Q)).
 
 If -> Then; _ :- If, !, Then.
 _ -> _; Else :- !, Else.
+Cond *-> Then; Else :- !, '$soft_cut'(Cond, Then, Else).
 
 P; Q :- call((P; Q)).
 
 If -> Then :- If, !, Then.
 
+Cond *-> Then :- Cond, Then.
+
 % Term unification
 
 X \= Y :- \+(X = Y).
 
+subsumes(General, Specific) :-
+  subsumes_term(General, Specific),
+  General = Specific.
+
 % Type testing
 
 atomic(X) :-
@@ -57,8 +66,6 @@ number(X) :- integer(X).
 callable(X) :- atom(X).
 callable(X) :- compound(X).
 
-ground(X) :- term_variables(X, []).
-
 % Term comparison
 
 X @=< Y :- compare(=, X, Y).
@@ -68,6 +75,8 @@ X == Y :- compare(=, X, Y).
 
 X \== Y :- \+(X == Y).
 
+X \=@= Y :- \+(X =@= Y).
+
 X @< Y :- compare(<, X, Y).
 
 X @> Y :- compare(>, X, Y).
@@ -77,10 +86,7 @@ X @>= Y :- compare(=, X, Y).
 
 % Clause creation and destruction
 
-retractall(Head) :-
-  retract((Head :- _)),
-  fail.
-retractall(_).
+assert(Clause) :- assertz(Clause).
 
 % Stream selection and control
 
@@ -139,6 +145,10 @@ put_code(S, Code) :-
   char_code(Char, Code),
   put_char(S, Char).
 
+tab(N) :-
+  current_output(S),
+  tab(S, N).
+
 nl :-
   current_output(S),
   nl(S).
@@ -194,10 +204,26 @@ write_canonical(Term) :-
 
 write_canonical(Stream, Term) :- write_term(Stream, Term, [quoted(true), ignore_ops(true)]).
 
+writeln(Term) :-
+  current_output(S),
+  writeln(S, Term).
+
+writeln(Stream, Term) :-
+  write(Stream, Term),
+  nl(Stream).
+
+print(Term) :-
+  current_output(S),
+  print(S, Term).
+
+print(Stream, Term) :- write_term(Stream, Term, [quoted(true), numbervars(true)]).
+
 % Logic and control
 
 once(P) :- P, !.
 
+ignore(P) :- (P -> true; true).
+
 false :- fail.
 
 % Atomic term processing
@@ -257,3 +283,27 @@ maplist(_Cont_7, [], [], [], [], [], [], []).
 maplist(Cont_7, [E1|E1s], [E2|E2s], [E3|E3s], [E4|E4s], [E5|E5s], [E6|E6s], [E7|E7s]) :-
   call(Cont_7, E1, E2, E3, E4, E5, E6, E7),
   maplist(Cont_7, E1s, E2s, E3s, E4s, E5s, E6s, E7s).
+
+foldl(Goal, List, V0, V) :-
+  foldl_(List, Goal, V0, V).
+
+foldl_([], _, V, V).
+foldl_([X|Xs], Goal, V0, V) :-
+  call(Goal, X, V0, V1),
+  foldl_(Xs, Goal, V1, V).
+
+foldl(Goal, List1, List2, V0, V) :-
+  foldl_(List1, List2, Goal, V0, V).
+
+foldl_([], [], _, V, V).
+foldl_([X|Xs], [Y|Ys], Goal, V0, V) :-
+  call(Goal, X, Y, V0, V1),
+  foldl_(Xs, Ys, Goal, V1, V).
+
+foldl(Goal, List1, List2, List3, V0, V) :-
+  foldl_(List1, List2, List3, Goal, V0, V).
+
+foldl_([], [], [], _, V, V).
+foldl_([X|Xs], [Y|Ys], [Z|Zs], Goal, V0, V) :-
+  call(Goal, X, Y, Z, V0, V1),
+  foldl_(Xs, Ys, Zs, Goal, V1, V).
@@ -46,21 +46,16 @@ See https://github.com/ichiban/prolog for more details.
 Type Ctrl-C or 'halt.' to exit.
 `, version)
 
-	halt := engine.Halt
+	restore := func() {}
 	if terminal.IsTerminal(0) {
 		oldState, err := terminal.MakeRaw(0)
 		if err != nil {
 			log.Panicf("failed to enter raw mode: %v", err)
 		}
-		restore := func() {
+		restore = func() {
 			_ = terminal.Restore(0, oldState)
 		}
 		defer restore()
-
-		halt = func(vm *engine.VM, n engine.Term, k engine.Cont, env *engine.Env) *engine.Promise {
-			restore()
-			return engine.Halt(vm, n, k, env)
-		}
 	}
 
 	t := terminal.NewTerminal(os.Stdin, prompt)
@@ -69,7 +64,9 @@ Type Ctrl-C or 'halt.' to exit.
 	log.SetOutput(t)
 
 	i := New(&userInput{t: t}, t)
-	i.Register1(engine.NewAtom("halt"), halt)
+	i.OnHalt = func(int) {
+		restore()
+	}
 	i.Unknown = func(name engine.Atom, args []engine.Term, env *engine.Env) {
 		var sb strings.Builder
 		s := engine.NewOutputTextStream(&sb)
@@ -79,6 +76,9 @@ Type Ctrl-C or 'halt.' to exit.
 
 	// Consult arguments.
 	if err := i.QuerySolution(`consult(?).`, flag.Args()).Err(); err != nil {
+		if code, ok := engine.ExitCode(err); ok {
+			os.Exit(code)
+		}
 		log.Panic(err)
 	}
 
@@ -164,6 +164,9 @@ func handleLine(ctx context.Context, buf *strings.Builder, p *prolog.Interpreter
 	}
 
 	if err := sols.Err(); err != nil {
+		if code, ok := engine.ExitCode(err); ok {
+			os.Exit(code)
+		}
 		log.Print(err)
 		return nil
 	}
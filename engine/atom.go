@@ -45,6 +45,7 @@ var (
 	atomSemiColon         = NewAtom(";")
 	atomNegation          = NewAtom(`\+`)
 	atomThen              = NewAtom("->")
+	atomSoftCut           = NewAtom("*->")
 	atomCaret             = NewAtom("^")
 	atomArrow             = NewAtom("-->")
 	atomBackSlash         = NewAtom(`\`)
@@ -52,6 +53,11 @@ var (
 	atomBitwiseLeftShift  = NewAtom("<<")
 	atomBitwiseAnd        = NewAtom(`/\`)
 	atomBitwiseOr         = NewAtom(`\/`)
+	atomAtLessThan        = NewAtom("@<")
+	atomAtLessThanOrEqual = NewAtom("@=<")
+	atomAtGreaterThan     = NewAtom("@>")
+	atomAtGreaterOrEqual  = NewAtom("@>=")
+	atomAtSign            = NewAtom("@")
 
 	atomAbs                     = NewAtom("abs")
 	atomAccess                  = NewAtom("access")
@@ -78,10 +84,14 @@ var (
 	atomChars                   = NewAtom("chars")
 	atomCloseOption             = NewAtom("close_option")
 	atomCodes                   = NewAtom("codes")
+	atomComments                = NewAtom("comments")
 	atomCompound                = NewAtom("compound")
 	atomCos                     = NewAtom("cos")
 	atomCreate                  = NewAtom("create")
+	atomCyclicTerm              = NewAtom("cyclic_term")
+	atomCycles                  = NewAtom("cycles")
 	atomDebug                   = NewAtom("debug")
+	atomDigitSeparators         = NewAtom("digit_separators")
 	atomDiscontiguous           = NewAtom("discontiguous")
 	atomDiv                     = NewAtom("div")
 	atomDomainError             = NewAtom("domain_error")
@@ -112,12 +122,16 @@ var (
 	atomFloatOverflow           = NewAtom("float_overflow")
 	atomFloor                   = NewAtom("floor")
 	atomForce                   = NewAtom("force")
+	atomFullStop                = NewAtom("fullstop")
+	atomGCD                     = NewAtom("gcd")
 	atomIOMode                  = NewAtom("io_mode")
 	atomIgnoreOps               = NewAtom("ignore_ops")
 	atomInByte                  = NewAtom("in_byte")
 	atomInCharacter             = NewAtom("in_character")
 	atomInCharacterCode         = NewAtom("in_character_code")
 	atomInclude                 = NewAtom("include")
+	atomInferenceLimitExceeded  = NewAtom("inference_limit_exceeded")
+	atomInformational           = NewAtom("informational")
 	atomInitialization          = NewAtom("initialization")
 	atomInput                   = NewAtom("input")
 	atomInstantiationError      = NewAtom("instantiation_error")
@@ -128,8 +142,10 @@ var (
 	atomLog                     = NewAtom("log")
 	atomMax                     = NewAtom("max")
 	atomMaxArity                = NewAtom("max_arity")
+	atomMaxDepth                = NewAtom("max_depth")
 	atomMaxInteger              = NewAtom("max_integer")
 	atomMemory                  = NewAtom("memory")
+	atomMessageHook             = NewAtom("message_hook")
 	atomMin                     = NewAtom("min")
 	atomMinInteger              = NewAtom("min_integer")
 	atomMod                     = NewAtom("mod")
@@ -141,6 +157,7 @@ var (
 	atomNotLessThanZero         = NewAtom("not_less_than_zero")
 	atomNumber                  = NewAtom("number")
 	atomNumberVars              = NewAtom("numbervars")
+	atomOccursCheck             = NewAtom("occurs_check")
 	atomOff                     = NewAtom("off")
 	atomOn                      = NewAtom("on")
 	atomOpen                    = NewAtom("open")
@@ -171,6 +188,7 @@ var (
 	atomRound                   = NewAtom("round")
 	atomSign                    = NewAtom("sign")
 	atomSin                     = NewAtom("sin")
+	atomSingleton               = NewAtom("singleton")
 	atomSingletons              = NewAtom("singletons")
 	atomSmallE                  = NewAtom("e")
 	atomSourceSink              = NewAtom("source_sink")
@@ -181,6 +199,7 @@ var (
 	atomStreamOrAlias           = NewAtom("stream_or_alias")
 	atomStreamPosition          = NewAtom("stream_position")
 	atomStreamProperty          = NewAtom("stream_property")
+	atomStyleName               = NewAtom("style_name")
 	atomSyntaxError             = NewAtom("syntax_error")
 	atomTan                     = NewAtom("tan")
 	atomTermExpansion           = NewAtom("term_expansion")
@@ -195,9 +214,11 @@ var (
 	atomUndefined               = NewAtom("undefined")
 	atomUnderflow               = NewAtom("underflow")
 	atomUnknown                 = NewAtom("unknown")
+	atomUserError               = NewAtom("user_error")
 	atomUserInput               = NewAtom("user_input")
 	atomUserOutput              = NewAtom("user_output")
 	atomVar                     = NewAtom("$VAR")
+	atomVariable                = NewAtom("variable")
 	atomVariableNames           = NewAtom("variable_names")
 	atomVariables               = NewAtom("variables")
 	atomWarning                 = NewAtom("warning")
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -34,6 +35,40 @@ func Negate(vm *VM, goal Term, k Cont, env *Env) *Promise {
 	})
 }
 
+// SoftCut runs then for every solution of cond. If cond has no solutions, it runs els instead.
+// It implements (cond *-> then; els).
+func SoftCut(vm *VM, cond, then, els Term, k Cont, env *Env) *Promise {
+	var found bool
+	return Delay(func(context.Context) *Promise {
+		return Call(vm, cond, func(env *Env) *Promise {
+			found = true
+			return Call(vm, then, k, env)
+		}, env)
+	}, func(context.Context) *Promise {
+		if found {
+			return Bool(false)
+		}
+		return Call(vm, els, k, env)
+	})
+}
+
+// ForAll succeeds iff action succeeds for every solution of cond, i.e. \+ (cond, \+ action).
+// The bindings made by cond and action don't leak to k.
+func ForAll(vm *VM, cond, action Term, k Cont, env *Env) *Promise {
+	return Delay(func(ctx context.Context) *Promise {
+		ok, err := Call(vm, cond, func(env *Env) *Promise {
+			return Negate(vm, action, Success, env)
+		}, env).Force(ctx)
+		if err != nil {
+			return Error(err)
+		}
+		if ok {
+			return Bool(false)
+		}
+		return k(env)
+	})
+}
+
 // Call executes goal. it succeeds if goal followed by k succeeds. A cut inside goal doesn't affect outside of Call.
 func Call(vm *VM, goal Term, k Cont, env *Env) *Promise {
 	switch g := env.Resolve(goal).(type) {
@@ -53,8 +88,7 @@ func Call(vm *VM, goal Term, k Cont, env *Env) *Promise {
 			return Error(err)
 		}
 
-		u := userDefined{clauses: cs}
-		return u.call(vm, args, k, env)
+		return cs.call(vm, args, k, env)
 	}
 }
 
@@ -151,8 +185,24 @@ func CallNth(vm *VM, goal, nth Term, k Cont, env *Env) *Promise {
 	return p
 }
 
-// Unify unifies x and y without occurs check (i.e., X = f(X) is allowed).
-func Unify(_ *VM, x, y Term, k Cont, env *Env) *Promise {
+// Unify unifies x and y without occurs check (i.e., X = f(X) is allowed) unless the occurs_check flag says otherwise.
+// If the flag is error, it raises an exception instead of failing when x and y are unifiable only without occurs check.
+func Unify(vm *VM, x, y Term, k Cont, env *Env) *Promise {
+	if vm != nil {
+		switch vm.occursCheck {
+		case occursCheckTrue:
+			return UnifyWithOccursCheck(vm, x, y, k, env)
+		case occursCheckError:
+			if e, ok := env.unifyWithOccursCheck(x, y); ok {
+				return k(e)
+			}
+			if _, ok := env.Unify(x, y); ok {
+				return Error(occursError(x, y, env))
+			}
+			return Bool(false)
+		}
+	}
+
 	env, ok := env.Unify(x, y)
 	if !ok {
 		return Bool(false)
@@ -225,30 +275,127 @@ func TypeCompound(_ *VM, t Term, k Cont, env *Env) *Promise {
 
 // AcyclicTerm checks if t is acyclic.
 func AcyclicTerm(_ *VM, t Term, k Cont, env *Env) *Promise {
-	if cyclicTerm(t, nil, env) {
+	if cyclicTerm(t, env) {
 		return Bool(false)
 	}
 	return k(env)
 }
 
-func cyclicTerm(t Term, visited []Term, env *Env) bool {
-	t = env.Resolve(t)
+// Ground checks if t contains no variables.
+func Ground(_ *VM, t Term, k Cont, env *Env) *Promise {
+	if !groundTerm(t, env) {
+		return Bool(false)
+	}
+	return k(env)
+}
 
-	for _, v := range visited {
-		if t == v {
-			return true
+// groundTerm reports whether t contains no variables in env. It terminates on cyclic terms.
+func groundTerm(t Term, env *Env) bool {
+	g, _ := ground(t, map[Variable]struct{}{}, env)
+	return g
+}
+
+// ground reports whether t is ground in env and whether it's ground regardless of env.
+// Only the latter is cached on compounds because the bindings in env are undone on backtracking.
+// It loops over the last arguments instead of recursing into them so that long lists don't go deep.
+func ground(t Term, visited map[Variable]struct{}, env *Env) (bool, bool) {
+	// The compounds and variables along the last arguments.
+	type link struct {
+		c      *compound // nil unless the link is a *compound.
+		static bool      // whether the arguments but the last are ground regardless of env.
+	}
+	var chain []link
+loop:
+	for {
+		switch u := t.(type) {
+		case Variable:
+			chain = append(chain, link{})
+			if _, ok := visited[u]; ok {
+				break loop // A cycle or a shared subterm which has been found ground so far.
+			}
+			visited[u] = struct{}{}
+			t = env.Resolve(u)
+			if _, ok := t.(Variable); ok {
+				return false, false
+			}
+		case *compound:
+			if u.knownGround() {
+				break loop
+			}
+			l := link{c: u, static: true}
+			for _, a := range u.args[:len(u.args)-1] {
+				g, s := ground(a, visited, env)
+				if !g {
+					return false, false
+				}
+				l.static = l.static && s
+			}
+			chain = append(chain, l)
+			t = u.args[len(u.args)-1]
+		case Compound:
+			l := link{static: true}
+			for i := 0; i < u.Arity()-1; i++ {
+				g, s := ground(u.Arg(i), visited, env)
+				if !g {
+					return false, false
+				}
+				l.static = l.static && s
+			}
+			chain = append(chain, l)
+			t = u.Arg(u.Arity() - 1)
+		default:
+			break loop
 		}
 	}
-	visited = append(visited, t)
 
-	if c, ok := t.(Compound); ok {
-		for i := 0; i < c.Arity(); i++ {
-			if cyclicTerm(c.Arg(i), visited, env) {
-				return true
-			}
+	static := true
+	for i := len(chain) - 1; i >= 0; i-- {
+		l := chain[i]
+		static = static && l.static
+		if static && l.c != nil {
+			l.c.markGround()
 		}
 	}
+	return true, static
+}
+
+// cyclicTerm reports whether t contains itself. It visits each subterm at most once.
+func cyclicTerm(t Term, env *Env) bool {
+	type frame struct {
+		t    Term
+		exit termID // Set when the frame marks the end of the visit of a compound.
+	}
+	var (
+		onPath = map[termID]struct{}{}
+		done   = map[termID]struct{}{}
+		stack  = []frame{{t: t}}
+	)
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if f.exit != nil {
+			delete(onPath, f.exit)
+			done[f.exit] = struct{}{}
+			continue
+		}
 
+		c, ok := env.Resolve(f.t).(Compound)
+		if !ok {
+			continue
+		}
+		k := id(c)
+		if _, ok := onPath[k]; ok {
+			return true
+		}
+		if _, ok := done[k]; ok {
+			continue
+		}
+		onPath[k] = struct{}{}
+		stack = append(stack, frame{exit: k})
+		for i := c.Arity() - 1; i >= 0; i-- {
+			stack = append(stack, frame{t: c.Arg(i)})
+		}
+	}
 	return false
 }
 
@@ -388,6 +535,124 @@ func CopyTerm(vm *VM, in, out Term, k Cont, env *Env) *Promise {
 	return Unify(vm, c, out, k, env)
 }
 
+// RenameTerm unifies renamed with a copy of t in which the variables are replaced by '$VAR'(0), '$VAR'(1), ... in the
+// order of their first occurrences. Thus, variants of a term rename to the same term.
+// Since they'd be indistinguishable from the renamed variables, t can't contain '$VAR'(N) where N is an integer.
+func RenameTerm(vm *VM, t, renamed Term, k Cont, env *Env) *Promise {
+	if n, ok := numberedVariable(t, env); ok {
+		return Error(typeError(validTypeVariable, n, env))
+	}
+
+	c, err := numberedCopy(t, map[termID]Term{}, map[Variable]Integer{}, env)
+	if err != nil {
+		return Error(err)
+	}
+	return Unify(vm, renamed, c, k, env)
+}
+
+// numberedVariable returns the first subterm of t in the form of '$VAR'(N) where N is an integer.
+func numberedVariable(t Term, env *Env) (Term, bool) {
+	var (
+		visited = map[termID]struct{}{}
+		stack   = []Term{t}
+	)
+	for len(stack) > 0 {
+		t, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		c, ok := env.Resolve(t).(Compound)
+		if !ok {
+			continue
+		}
+		if c.Functor() == atomVar && c.Arity() == 1 {
+			if _, ok := env.Resolve(c.Arg(0)).(Integer); ok {
+				return c, true
+			}
+		}
+		if _, ok := visited[id(c)]; ok {
+			continue
+		}
+		visited[id(c)] = struct{}{}
+		for i := c.Arity() - 1; i >= 0; i-- {
+			stack = append(stack, c.Arg(i))
+		}
+	}
+	return nil, false
+}
+
+// numberedCopy returns a copy of t in which every free variable is replaced by '$VAR'(N).
+// Each occurrence gets its own '$VAR'(N) so that the copy doesn't share them.
+func numberedCopy(t Term, copied map[termID]Term, numbers map[Variable]Integer, env *Env) (Term, error) {
+	t = env.Resolve(t)
+	if c, ok := copied[id(t)]; ok {
+		return c, nil
+	}
+	if c, ok := t.(*compound); ok && c.knownGround() {
+		return c, nil
+	}
+	switch t := t.(type) {
+	case Variable:
+		n, ok := numbers[t]
+		if !ok {
+			n = Integer(len(numbers))
+			numbers[t] = n
+		}
+		return atomVar.Apply(n), nil
+	case charList, codeList:
+		return t, nil
+	case list:
+		s, err := makeSlice(len(t))
+		if err != nil {
+			return nil, resourceError(resourceMemory, env)
+		}
+		l := list(s)
+		copied[id(t)] = l
+		for i := range t {
+			c, err := numberedCopy(t[i], copied, numbers, env)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = c
+		}
+		return l, nil
+	case *partial:
+		var p partial
+		copied[id(t)] = &p
+		cp, err := numberedCopy(t.Compound, copied, numbers, env)
+		if err != nil {
+			return nil, err
+		}
+		p.Compound = cp.(Compound)
+		cp, err = numberedCopy(*t.tail, copied, numbers, env)
+		if err != nil {
+			return nil, err
+		}
+		tail := cp
+		p.tail = &tail
+		return &p, nil
+	case Compound:
+		args, err := makeSlice(t.Arity())
+		if err != nil {
+			return nil, resourceError(resourceMemory, env)
+		}
+		c := compound{
+			functor: t.Functor(),
+			args:    args,
+		}
+		copied[id(t)] = &c
+		for i := 0; i < t.Arity(); i++ {
+			cp, err := numberedCopy(t.Arg(i), copied, numbers, env)
+			if err != nil {
+				return nil, err
+			}
+			c.args[i] = cp
+		}
+		return &c, nil
+	default:
+		return t, nil
+	}
+}
+
+// renamedCopy returns a copy of t in which every free variable is replaced by a fresh one.
+// The same variable is replaced by the same fresh variable so that sharing is preserved.
 func renamedCopy(t Term, copied map[termID]Term, env *Env) (Term, error) {
 	if copied == nil {
 		copied = map[termID]Term{}
@@ -396,6 +661,9 @@ func renamedCopy(t Term, copied map[termID]Term, env *Env) (Term, error) {
 	if c, ok := copied[id(t)]; ok {
 		return c, nil
 	}
+	if c, ok := t.(*compound); ok && c.knownGround() {
+		return c, nil
+	}
 	switch t := t.(type) {
 	case Variable:
 		v := NewVariable()
@@ -458,6 +726,23 @@ func renamedCopy(t Term, copied map[termID]Term, env *Env) (Term, error) {
 
 // TermVariables succeeds if vars unifies with a list of variables in term.
 func TermVariables(vm *VM, term, vars Term, k Cont, env *Env) *Promise {
+	ret, err := termVariables(term, env)
+	if err != nil {
+		return Error(err)
+	}
+
+	iter := ListIterator{List: vars, Env: env, AllowPartial: true}
+	for iter.Next() {
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	return Unify(vm, vars, List(ret...), k, env)
+}
+
+// termVariables returns the variables in term in the left-to-right traversal order.
+func termVariables(term Term, env *Env) ([]Term, error) {
 	var (
 		witness  = map[Variable]struct{}{}
 		ret      []Term
@@ -475,7 +760,7 @@ func TermVariables(vm *VM, term, vars Term, k Cont, env *Env) *Promise {
 		case Compound:
 			args, err := makeSlice(t.Arity())
 			if err != nil {
-				return Error(resourceError(resourceMemory, env))
+				return nil, resourceError(resourceMemory, env)
 			}
 			for i := 0; i < t.Arity(); i++ {
 				args[i] = t.Arg(i)
@@ -483,15 +768,7 @@ func TermVariables(vm *VM, term, vars Term, k Cont, env *Env) *Promise {
 			traverse = append(args, traverse...)
 		}
 	}
-
-	iter := ListIterator{List: vars, Env: env, AllowPartial: true}
-	for iter.Next() {
-	}
-	if err := iter.Err(); err != nil {
-		return Error(err)
-	}
-
-	return Unify(vm, vars, List(ret...), k, env)
+	return ret, nil
 }
 
 var operatorSpecifiers = map[Atom]operatorSpecifier{
@@ -699,13 +976,18 @@ func assertMerge(vm *VM, t Term, merge func([]clause, []clause) []clause, env *E
 		}
 	}
 
+	if cyclicTerm(t, env) {
+		return representationError(flagCyclicTerm, env)
+	}
+
 	if vm.procedures == nil {
 		vm.procedures = map[procedureIndicator]procedure{}
 	}
 	p, ok := vm.procedures[pi]
-	if !ok {
-		p = &userDefined{dynamic: true}
-		vm.procedures[pi] = p
+	if ok {
+		if u, ok := p.(*userDefined); !ok || !u.dynamic {
+			return permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), env)
+		}
 	}
 
 	added, err := compile(t, env)
@@ -713,10 +995,11 @@ func assertMerge(vm *VM, t Term, merge func([]clause, []clause) []clause, env *E
 		return err
 	}
 
-	u, ok := p.(*userDefined)
-	if !ok || !u.dynamic {
-		return permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), env)
+	if !ok {
+		p = &userDefined{dynamic: true}
+		vm.procedures[pi] = p
 	}
+	u := p.(*userDefined)
 
 	u.clauses = merge(u.clauses, added)
 	return nil
@@ -793,8 +1076,9 @@ func collectionOf(vm *VM, agg func([]Term, *Env) Term, template, goal, instances
 	}, env)
 }
 
+// variant reports whether t1 and t2 are identical up to a consistent renaming of variables.
 func variant(t1, t2 Term, env *Env) bool {
-	s := map[Variable]Variable{}
+	s, r := map[Variable]Variable{}, map[Variable]Variable{}
 	rest := [][2]Term{
 		{t1, t2},
 	}
@@ -806,12 +1090,13 @@ func variant(t1, t2 Term, env *Env) bool {
 		case Variable:
 			switch y := y.(type) {
 			case Variable:
-				if z, ok := s[x]; ok {
-					if z != y {
-						return false
-					}
-				} else {
-					s[x] = y
+				z, ok1 := s[x]
+				w, ok2 := r[y]
+				switch {
+				case !ok1 && !ok2:
+					s[x], r[y] = y, x
+				case z != y || w != x:
+					return false
 				}
 			default:
 				return false
@@ -849,6 +1134,15 @@ func iteratedGoalTerm(t Term, env *Env) Term {
 
 // FindAll collects all the solutions of goal as instances, which unify with template. instances may contain duplications.
 func FindAll(vm *VM, template, goal, instances Term, k Cont, env *Env) *Promise {
+	return findAll(vm, template, goal, instances, atomEmptyList, k, env)
+}
+
+// FindAll4 is similar to FindAll but instances is a difference list ending with tail.
+func FindAll4(vm *VM, template, goal, instances, tail Term, k Cont, env *Env) *Promise {
+	return findAll(vm, template, goal, instances, tail, k, env)
+}
+
+func findAll(vm *VM, template, goal, instances, tail Term, k Cont, env *Env) *Promise {
 	iter := ListIterator{List: instances, Env: env, AllowPartial: true}
 	for iter.Next() {
 	}
@@ -867,7 +1161,10 @@ func FindAll(vm *VM, template, goal, instances Term, k Cont, env *Env) *Promise
 		}, env).Force(ctx); err != nil {
 			return Error(err)
 		}
-		return Unify(vm, instances, List(answers...), k, env)
+		if tail == atomEmptyList {
+			return Unify(vm, instances, List(answers...), k, env)
+		}
+		return Unify(vm, instances, PartialList(tail, answers...), k, env)
 	})
 }
 
@@ -897,6 +1194,14 @@ func Compare(vm *VM, order, term1, term2 Term, k Cont, env *Env) *Promise {
 	}
 }
 
+// Variant succeeds iff term1 and term2 are identical up to a consistent renaming of variables.
+func Variant(_ *VM, term1, term2 Term, k Cont, env *Env) *Promise {
+	if !variant(term1, term2, env) {
+		return Bool(false)
+	}
+	return k(env)
+}
+
 // Between succeeds when lower, upper, and value are all integers, and lower <= value <= upper.
 // If value is a variable, it is unified with successive integers from lower to upper.
 func Between(vm *VM, lower, upper, value Term, k Cont, env *Env) *Promise {
@@ -967,72 +1272,285 @@ func Sort(vm *VM, list, sorted Term, k Cont, env *Env) *Promise {
 	return Unify(vm, sorted, env.set(elems...), k, env)
 }
 
-// KeySort succeeds if sorted is a sorted list of pairs based on their keys.
-func KeySort(vm *VM, pairs, sorted Term, k Cont, env *Env) *Promise {
+// MSort succeeds if sorted is a sorted list of list. Unlike Sort, duplicates are retained.
+func MSort(vm *VM, list, sorted Term, k Cont, env *Env) *Promise {
 	var elems []Term
-	iter := ListIterator{List: pairs, Env: env}
+	iter := ListIterator{List: list, Env: env}
 	for iter.Next() {
-		switch e := env.Resolve(iter.Current()).(type) {
-		case Variable:
-			return Error(InstantiationError(env))
-		case Compound:
-			if e.Functor() != atomMinus || e.Arity() != 2 {
-				return Error(typeError(validTypePair, e, env))
-			}
-			elems = append(elems, e)
-		default:
-			return Error(typeError(validTypePair, e, env))
-		}
+		elems = append(elems, env.Resolve(iter.Current()))
 	}
 	if err := iter.Err(); err != nil {
 		return Error(err)
 	}
 
-	switch s := env.Resolve(sorted).(type) {
-	case Variable:
-		break
-	default:
-		iter := ListIterator{List: s, Env: env}
-		for iter.Next() {
-			switch e := env.Resolve(iter.Current()).(type) {
-			case Variable:
-				continue
-			case Compound:
-				if e.Functor() != atomMinus || e.Arity() != 2 {
-					return Error(typeError(validTypePair, e, env))
-				}
-			default:
-				return Error(typeError(validTypePair, e, env))
-			}
-		}
-		if err := iter.Err(); err != nil {
-			return Error(err)
-		}
+	iter = ListIterator{List: sorted, Env: env, AllowPartial: true}
+	for iter.Next() {
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
 	}
 
 	sort.SliceStable(elems, func(i, j int) bool {
-		return elems[i].(Compound).Arg(0).Compare(elems[j].(Compound).Arg(0), env) == -1
+		return elems[i].Compare(elems[j], env) == -1
 	})
 
 	return Unify(vm, sorted, List(elems...), k, env)
 }
 
-// Throw throws ball as an exception.
-func Throw(_ *VM, ball Term, _ Cont, env *Env) *Promise {
-	switch b := env.Resolve(ball).(type) {
+// Sort4 succeeds if sorted is a sorted list of list based on the key-th argument of each element and order.
+// If key is 0, the whole elements are compared. Duplicates are removed only if order is either @< or @>.
+func Sort4(vm *VM, key, order, list, sorted Term, k Cont, env *Env) *Promise {
+	var n int
+	switch key := env.Resolve(key).(type) {
 	case Variable:
 		return Error(InstantiationError(env))
+	case Integer:
+		if key < 0 {
+			return Error(domainError(validDomainNotLessThanZero, key, env))
+		}
+		n = int(key)
 	default:
-		return Error(NewException(b, env))
+		return Error(typeError(validTypeInteger, key, env))
 	}
-}
 
-// Catch calls goal. If an exception is thrown and unifies with catcher, it calls recover.
-func Catch(vm *VM, goal, catcher, recover Term, k Cont, env *Env) *Promise {
-	return catch(func(err error) *Promise {
-		e, ok := err.(Exception)
-		if !ok {
-			e = Exception{term: atomError.Apply(NewAtom("system_error"), NewAtom(err.Error()))}
+	var (
+		desc, dedup bool
+	)
+	switch o := env.Resolve(order).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	case Atom:
+		switch o {
+		case atomAtLessThan:
+			dedup = true
+		case atomAtLessThanOrEqual:
+			break
+		case atomAtGreaterThan:
+			desc, dedup = true, true
+		case atomAtGreaterOrEqual:
+			desc = true
+		default:
+			return Error(domainError(validDomainOrder, o, env))
+		}
+	default:
+		return Error(typeError(validTypeAtom, o, env))
+	}
+
+	var elems []Term
+	iter := ListIterator{List: list, Env: env}
+	for iter.Next() {
+		e := env.Resolve(iter.Current())
+		if n > 0 {
+			if _, ok := e.(Variable); ok {
+				return Error(InstantiationError(env))
+			}
+			c, ok := e.(Compound)
+			if !ok || c.Arity() < n {
+				return Error(typeError(validTypeCompound, e, env))
+			}
+		}
+		elems = append(elems, e)
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	iter = ListIterator{List: sorted, Env: env, AllowPartial: true}
+	for iter.Next() {
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	keyOf := func(t Term) Term {
+		if n == 0 {
+			return t
+		}
+		return t.(Compound).Arg(n - 1)
+	}
+	compare := func(i, j int) int {
+		o := keyOf(elems[i]).Compare(keyOf(elems[j]), env)
+		if desc {
+			o = -o
+		}
+		return o
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		return compare(i, j) == -1
+	})
+
+	if dedup {
+		us := elems[:0]
+		for _, e := range elems {
+			if len(us) > 0 && keyOf(us[len(us)-1]).Compare(keyOf(e), env) == 0 {
+				continue
+			}
+			us = append(us, e)
+		}
+		elems = us
+	}
+
+	return Unify(vm, sorted, List(elems...), k, env)
+}
+
+// PredSort succeeds if sorted is a sorted list of list based on the order determined by call(pred, Order, A, B).
+// pred has to unify Order with one of <, =, and >. If Order is =, only the first of the elements is retained.
+// It's a merge sort so the elements are compared in a stable manner.
+func PredSort(vm *VM, pred, list, sorted Term, k Cont, env *Env) *Promise {
+	var elems []Term
+	iter := ListIterator{List: list, Env: env}
+	for iter.Next() {
+		elems = append(elems, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	iter = ListIterator{List: sorted, Env: env, AllowPartial: true}
+	for iter.Next() {
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	return Delay(func(ctx context.Context) *Promise {
+		compare := func(a, b Term) (Atom, bool, error) {
+			order := NewVariable()
+			var o Term
+			ok, err := Call3(vm, pred, order, a, b, func(env *Env) *Promise {
+				o = env.Resolve(order)
+				return Bool(true)
+			}, env).Force(ctx)
+			if err != nil || !ok {
+				return 0, ok, err
+			}
+			switch o := o.(type) {
+			case Variable:
+				return 0, false, InstantiationError(env)
+			case Atom:
+				switch o {
+				case atomLessThan, atomEqual, atomGreaterThan:
+					return o, true, nil
+				}
+			}
+			return 0, false, domainError(validDomainOrder, o, env)
+		}
+
+		elems, ok, err := predSort(elems, compare)
+		if err != nil {
+			return Error(err)
+		}
+		if !ok {
+			return Bool(false)
+		}
+		return Unify(vm, sorted, List(elems...), k, env)
+	})
+}
+
+func predSort(elems []Term, compare func(a, b Term) (Atom, bool, error)) ([]Term, bool, error) {
+	if len(elems) < 2 {
+		return elems, true, nil
+	}
+
+	left, ok, err := predSort(elems[:len(elems)/2], compare)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	right, ok, err := predSort(elems[len(elems)/2:], compare)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	merged := make([]Term, 0, len(left)+len(right))
+	for len(left) > 0 && len(right) > 0 {
+		o, ok, err := compare(left[0], right[0])
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+		switch o {
+		case atomLessThan:
+			merged, left = append(merged, left[0]), left[1:]
+		case atomEqual:
+			merged, left, right = append(merged, left[0]), left[1:], right[1:]
+		default:
+			merged, right = append(merged, right[0]), right[1:]
+		}
+	}
+	merged = append(merged, left...)
+	merged = append(merged, right...)
+	return merged, true, nil
+}
+
+// KeySort succeeds if sorted is a sorted list of pairs based on their keys.
+func KeySort(vm *VM, pairs, sorted Term, k Cont, env *Env) *Promise {
+	var elems []Term
+	iter := ListIterator{List: pairs, Env: env}
+	for iter.Next() {
+		switch e := env.Resolve(iter.Current()).(type) {
+		case Variable:
+			return Error(InstantiationError(env))
+		case Compound:
+			if e.Functor() != atomMinus || e.Arity() != 2 {
+				return Error(typeError(validTypePair, e, env))
+			}
+			elems = append(elems, e)
+		default:
+			return Error(typeError(validTypePair, e, env))
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	switch s := env.Resolve(sorted).(type) {
+	case Variable:
+		break
+	default:
+		iter := ListIterator{List: s, Env: env}
+		for iter.Next() {
+			switch e := env.Resolve(iter.Current()).(type) {
+			case Variable:
+				continue
+			case Compound:
+				if e.Functor() != atomMinus || e.Arity() != 2 {
+					return Error(typeError(validTypePair, e, env))
+				}
+			default:
+				return Error(typeError(validTypePair, e, env))
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return Error(err)
+		}
+	}
+
+	sort.SliceStable(elems, func(i, j int) bool {
+		return elems[i].(Compound).Arg(0).Compare(elems[j].(Compound).Arg(0), env) == -1
+	})
+
+	return Unify(vm, sorted, List(elems...), k, env)
+}
+
+// Throw throws ball as an exception.
+func Throw(_ *VM, ball Term, _ Cont, env *Env) *Promise {
+	switch b := env.Resolve(ball).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	default:
+		return Error(NewException(b, env))
+	}
+}
+
+// Catch calls goal. If an exception is thrown and unifies with catcher, it calls recover.
+func Catch(vm *VM, goal, catcher, recover Term, k Cont, env *Env) *Promise {
+	return catch(func(err error) *Promise {
+		if _, ok := err.(haltError); ok {
+			return nil
+		}
+
+		e, ok := err.(Exception)
+		if !ok {
+			e = Exception{term: atomError.Apply(NewAtom("system_error"), NewAtom(err.Error()))}
 		}
 
 		env, ok := env.Unify(catcher, e.term)
@@ -1046,6 +1564,66 @@ func Catch(vm *VM, goal, catcher, recover Term, k Cont, env *Env) *Promise {
 	})
 }
 
+// CallWithInferenceLimit calls goal with at most limit predicate calls.
+// If goal succeeds without leaving choice points, result is unified with !. If goal succeeds with choice points, result is unified with true.
+// If goal exceeds the limit, result is unified with inference_limit_exceeded.
+func CallWithInferenceLimit(vm *VM, goal, limit, result Term, k Cont, env *Env) *Promise {
+	return callWithInferenceLimit(vm, goal, limit, result, nil, k, env)
+}
+
+// CallWithInferenceLimit4 is similar to CallWithInferenceLimit but also unifies remaining with the number of predicate calls left.
+func CallWithInferenceLimit4(vm *VM, goal, limit, result, remaining Term, k Cont, env *Env) *Promise {
+	return callWithInferenceLimit(vm, goal, limit, result, remaining, k, env)
+}
+
+func callWithInferenceLimit(vm *VM, goal, limit, result, remaining Term, k Cont, env *Env) *Promise {
+	var n Integer
+	switch l := env.Resolve(limit).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	case Integer:
+		if l < 0 {
+			return Error(domainError(validDomainNotLessThanZero, l, env))
+		}
+		n = l
+	default:
+		return Error(typeError(validTypeInteger, l, env))
+	}
+
+	unify := func(r Atom, left Integer, env *Env) *Promise {
+		if remaining == nil {
+			return Unify(vm, result, r, k, env)
+		}
+		return Unify(vm, tuple(result, remaining), tuple(r, left), k, env)
+	}
+
+	vm.inferenceLimited.Store(true)
+	parent, _ := env.inferenceCounter()
+	inner, c := env.limitInferences(uint64(n))
+	var p *Promise
+	p = catch(func(err error) *Promise {
+		if _, ok := err.(Exception); !ok || !c.exceeded() {
+			return nil
+		}
+		return unify(atomInferenceLimitExceeded, 0, env)
+	}, func(ctx context.Context) *Promise {
+		return Call(vm, goal, func(env *Env) *Promise {
+			env = env.bind(varInference, parent)
+			if c.exceeded() { // goal caught the error and succeeded without any more calls.
+				return unify(atomInferenceLimitExceeded, 0, env)
+			}
+			left := Integer(c.limit - c.count)
+			return Delay(func(ctx context.Context) *Promise {
+				if hasChoicesSince(ctx, p) {
+					return unify(atomTrue, left, env)
+				}
+				return unify(atomCut, left, env)
+			})
+		}, inner)
+	})
+	return p
+}
+
 // CurrentPredicate matches pi with a predicate indicator of the user-defined procedures in the database.
 func CurrentPredicate(vm *VM, pi Term, k Cont, env *Env) *Promise {
 	switch pi := env.Resolve(pi).(type) {
@@ -1117,43 +1695,160 @@ func Retract(vm *VM, t Term, k Cont, env *Env) *Promise {
 	return Delay(ks...)
 }
 
+// RetractAll removes every clause whose head unifies with head. It succeeds even if there's no such clause.
+func RetractAll(vm *VM, head Term, k Cont, env *Env) *Promise {
+	pi, _, err := piArg(head, env)
+	if err != nil {
+		return Error(err)
+	}
+
+	p, ok := vm.procedures[pi]
+	if !ok {
+		if vm.procedures == nil {
+			vm.procedures = map[procedureIndicator]procedure{}
+		}
+		vm.procedures[pi] = &userDefined{dynamic: true}
+		return k(env)
+	}
+
+	u, ok := p.(*userDefined)
+	if !ok || !u.dynamic {
+		return Error(permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), env))
+	}
+
+	cs := make([]clause, 0, len(u.clauses))
+	for _, c := range u.clauses {
+		if _, ok := env.Unify(head, rulify(c.raw, env).(Compound).Arg(0)); ok {
+			continue
+		}
+		cs = append(cs, c)
+	}
+	u.clauses = cs
+	return k(env)
+}
+
+// Dynamic declares the procedures indicated by pi dynamic. pi is either a predicate indicator, a conjunction of them, or a list of them.
+// A dynamic procedure without clauses simply fails when called.
+func Dynamic(vm *VM, pi Term, k Cont, env *Env) *Promise {
+	var pis []procedureIndicator
+	iter := anyIterator{Any: pi, Env: env}
+	for iter.Next() {
+		p, err := predicateIndicator(iter.Current(), env)
+		if err != nil {
+			return Error(err)
+		}
+		pis = append(pis, p)
+	}
+	if err := iter.Err(); err != nil {
+		return Error(err)
+	}
+
+	for _, pi := range pis {
+		if p, ok := vm.procedures[pi]; ok {
+			if u, ok := p.(*userDefined); !ok || u.frozen {
+				return Error(permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), env))
+			}
+		}
+	}
+
+	if vm.procedures == nil {
+		vm.procedures = map[procedureIndicator]procedure{}
+	}
+	for _, pi := range pis {
+		u, ok := vm.procedures[pi].(*userDefined)
+		if !ok {
+			u = &userDefined{}
+			vm.procedures[pi] = u
+		}
+		u.dynamic = true
+		u.public = true
+	}
+	return k(env)
+}
+
 // Abolish removes the procedure indicated by pi from the database.
+// pi can be either a predicate indicator or a list of them.
+// If any of them is invalid, none of the procedures are removed.
 func Abolish(vm *VM, pi Term, k Cont, env *Env) *Promise {
+	var pis []procedureIndicator
+	switch l := env.Resolve(pi).(type) {
+	case Compound:
+		if l.Functor() != atomDot || l.Arity() != 2 {
+			p, err := predicateIndicator(l, env)
+			if err != nil {
+				return Error(err)
+			}
+			pis = append(pis, p)
+			break
+		}
+		iter := ListIterator{List: l, Env: env}
+		for iter.Next() {
+			p, err := predicateIndicator(iter.Current(), env)
+			if err != nil {
+				return Error(err)
+			}
+			pis = append(pis, p)
+		}
+		if err := iter.Err(); err != nil {
+			return Error(err)
+		}
+	case Atom:
+		if l != atomEmptyList {
+			return Error(typeError(validTypePredicateIndicator, l, env))
+		}
+	default:
+		p, err := predicateIndicator(l, env)
+		if err != nil {
+			return Error(err)
+		}
+		pis = append(pis, p)
+	}
+
+	for _, pi := range pis {
+		p, ok := vm.procedures[pi]
+		if !ok {
+			continue
+		}
+		if u, ok := p.(*userDefined); !ok || !u.dynamic {
+			return Error(permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), env))
+		}
+	}
+
+	for _, pi := range pis {
+		delete(vm.procedures, pi)
+	}
+	return k(env)
+}
+
+// predicateIndicator converts pi of the form Name/Arity into a procedureIndicator.
+func predicateIndicator(pi Term, env *Env) (procedureIndicator, error) {
 	switch pi := env.Resolve(pi).(type) {
 	case Variable:
-		return Error(InstantiationError(env))
+		return procedureIndicator{}, InstantiationError(env)
 	case Compound:
 		if pi.Functor() != atomSlash || pi.Arity() != 2 {
-			return Error(typeError(validTypePredicateIndicator, pi, env))
+			return procedureIndicator{}, typeError(validTypePredicateIndicator, pi, env)
 		}
-
-		name, arity := pi.Arg(0), pi.Arg(1)
-
-		switch name := env.Resolve(name).(type) {
+		switch name := env.Resolve(pi.Arg(0)).(type) {
 		case Variable:
-			return Error(InstantiationError(env))
+			return procedureIndicator{}, InstantiationError(env)
 		case Atom:
-			switch arity := env.Resolve(arity).(type) {
+			switch arity := env.Resolve(pi.Arg(1)).(type) {
 			case Variable:
-				return Error(InstantiationError(env))
+				return procedureIndicator{}, InstantiationError(env)
 			case Integer:
 				if arity < 0 {
-					return Error(domainError(validDomainNotLessThanZero, arity, env))
-				}
-				key := procedureIndicator{name: name, arity: arity}
-				if u, ok := vm.procedures[key].(*userDefined); !ok || !u.dynamic {
-					return Error(permissionError(operationModify, permissionTypeStaticProcedure, key.Term(), env))
+					return procedureIndicator{}, domainError(validDomainNotLessThanZero, arity, env)
 				}
-				delete(vm.procedures, key)
-				return k(env)
+				return procedureIndicator{name: name, arity: arity}, nil
 			default:
-				return Error(typeError(validTypeInteger, arity, env))
+				return procedureIndicator{}, typeError(validTypeInteger, arity, env)
 			}
 		default:
-			return Error(typeError(validTypeAtom, name, env))
+			return procedureIndicator{}, typeError(validTypeAtom, name, env)
 		}
 	default:
-		return Error(typeError(validTypePredicateIndicator, pi, env))
+		return procedureIndicator{}, typeError(validTypePredicateIndicator, pi, env)
 	}
 }
 
@@ -1161,7 +1856,7 @@ func Abolish(vm *VM, pi Term, k Cont, env *Env) *Promise {
 func CurrentInput(vm *VM, stream Term, k Cont, env *Env) *Promise {
 	switch env.Resolve(stream).(type) {
 	case Variable, *Stream:
-		return Unify(vm, stream, vm.input, k, env)
+		return Unify(vm, stream, vm.currentInput(), k, env)
 	default:
 		return Error(domainError(validDomainStream, stream, env))
 	}
@@ -1171,7 +1866,7 @@ func CurrentInput(vm *VM, stream Term, k Cont, env *Env) *Promise {
 func CurrentOutput(vm *VM, stream Term, k Cont, env *Env) *Promise {
 	switch env.Resolve(stream).(type) {
 	case Variable, *Stream:
-		return Unify(vm, stream, vm.output, k, env)
+		return Unify(vm, stream, vm.currentOutput(), k, env)
 	default:
 		return Error(domainError(validDomainStream, stream, env))
 	}
@@ -1188,7 +1883,7 @@ func SetInput(vm *VM, streamOrAlias Term, k Cont, env *Env) *Promise {
 		return Error(permissionError(operationInput, permissionTypeStream, streamOrAlias, env))
 	}
 
-	vm.input = s
+	vm.setInput(s)
 	return k(env)
 }
 
@@ -1203,7 +1898,7 @@ func SetOutput(vm *VM, streamOrAlias Term, k Cont, env *Env) *Promise {
 		return Error(permissionError(operationOutput, permissionTypeStream, streamOrAlias, env))
 	}
 
-	vm.output = s
+	vm.setOutput(s)
 	return k(env)
 }
 
@@ -1467,6 +2162,7 @@ func WriteTerm(vm *VM, streamOrAlias, t, options Term, k Cont, env *Env) *Promis
 	opts := WriteOptions{
 		ops:      vm.operators,
 		priority: 1200,
+		vm:       vm,
 	}
 	iter := ListIterator{List: options, Env: env}
 	for iter.Next() {
@@ -1488,13 +2184,38 @@ func WriteTerm(vm *VM, streamOrAlias, t, options Term, k Cont, env *Env) *Promis
 		return Error(err)
 	}
 
-	if err := env.Resolve(t).WriteTerm(w, &opts, env); err != nil {
+	lw := lastRuneWriter{w: w}
+	if err := env.Resolve(t).WriteTerm(&lw, &opts, env); err != nil {
 		return Error(err)
 	}
 
+	if opts.fullStop {
+		// Put a space in between so that the period won't be a part of a graphic token.
+		end := ".\n"
+		if isGraphicChar(lw.last) {
+			end = " .\n"
+		}
+		if _, err := io.WriteString(w, end); err != nil {
+			return Error(err)
+		}
+	}
+
 	return k(env)
 }
 
+// lastRuneWriter is an io.Writer which remembers the last rune written.
+type lastRuneWriter struct {
+	w    io.Writer
+	last rune
+}
+
+func (l *lastRuneWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		l.last, _ = utf8.DecodeLastRune(p)
+	}
+	return l.w.Write(p)
+}
+
 func writeTermOption(opts *WriteOptions, option Term, env *Env) error {
 	switch o := env.Resolve(option).(type) {
 	case Variable:
@@ -1513,6 +2234,21 @@ func writeTermOption(opts *WriteOptions, option Term, env *Env) error {
 			return nil
 		}
 
+		if o.Functor() == atomMaxDepth {
+			switch n := env.Resolve(o.Arg(0)).(type) {
+			case Variable:
+				return InstantiationError(env)
+			case Integer:
+				if n < 0 {
+					return domainError(validDomainWriteOption, o, env)
+				}
+				opts.maxDepth = n
+				return nil
+			default:
+				return domainError(validDomainWriteOption, o, env)
+			}
+		}
+
 		var b bool
 		switch v := env.Resolve(o.Arg(0)).(type) {
 		case Variable:
@@ -1540,6 +2276,9 @@ func writeTermOption(opts *WriteOptions, option Term, env *Env) error {
 		case atomNumberVars:
 			opts.numberVars = b
 			return nil
+		case atomFullStop:
+			opts.fullStop = b
+			return nil
 		default:
 			return domainError(validDomainWriteOption, o, env)
 		}
@@ -1610,13 +2349,11 @@ func CharCode(vm *VM, char, code Term, k Cont, env *Env) *Promise {
 		case Variable:
 			return Error(InstantiationError(env))
 		case Integer:
-			r := rune(cd)
-
-			if !utf8.ValidRune(r) {
+			if cd < 0 || cd > unicode.MaxRune || !utf8.ValidRune(rune(cd)) {
 				return Error(representationError(flagCharacterCode, env))
 			}
 
-			return Unify(vm, ch, Atom(r), k, env)
+			return Unify(vm, ch, Atom(cd), k, env)
 		default:
 			return Error(typeError(validTypeInteger, code, env))
 		}
@@ -1701,10 +2438,53 @@ func PutChar(vm *VM, streamOrAlias, char Term, k Cont, env *Env) *Promise {
 	}
 }
 
+// Tab writes n spaces to the stream represented by streamOrAlias where n is evaluated arithmetically.
+func Tab(vm *VM, streamOrAlias, n Term, k Cont, env *Env) *Promise {
+	s, err := stream(vm, streamOrAlias, env)
+	if err != nil {
+		return Error(err)
+	}
+
+	v, err := eval(n, env)
+	if err != nil {
+		return Error(err)
+	}
+	i, ok := v.(Integer)
+	if !ok {
+		return Error(typeError(validTypeInteger, v, env))
+	}
+	if i < 0 {
+		return Error(domainError(validDomainNotLessThanZero, i, env))
+	}
+
+	w, err := s.textWriter()
+	switch {
+	case errors.Is(err, errWrongIOMode):
+		return Error(permissionError(operationOutput, permissionTypeStream, streamOrAlias, env))
+	case errors.Is(err, errWrongStreamType):
+		return Error(permissionError(operationOutput, permissionTypeBinaryStream, streamOrAlias, env))
+	case err != nil:
+		return Error(err)
+	}
+
+	for ; i > 0; i-- {
+		if _, err := io.WriteString(w, " "); err != nil {
+			return Error(err)
+		}
+	}
+
+	return k(env)
+}
+
 type readTermOptions struct {
 	singletons    Term
 	variables     Term
 	variableNames Term
+	comments      Term
+	cycles        bool
+
+	// keepComments is set if comments(_) is given so that the lexer collects comments only when needed.
+	keepComments bool
 }
 
 // ReadTerm reads from the stream represented by streamOrAlias and unifies with stream.
@@ -1714,32 +2494,28 @@ func ReadTerm(vm *VM, streamOrAlias, out, options Term, k Cont, env *Env) *Promi
 		return Error(err)
 	}
 
-	opts := readTermOptions{
-		singletons:    NewVariable(),
-		variables:     NewVariable(),
-		variableNames: NewVariable(),
-	}
-	iter := ListIterator{List: options, Env: env}
-	for iter.Next() {
-		if err := readTermOption(&opts, iter.Current(), env); err != nil {
-			return Error(err)
-		}
-	}
-	if err := iter.Err(); err != nil {
+	opts, err := newReadTermOptions(options, env)
+	if err != nil {
 		return Error(err)
 	}
 
-	p := NewParser(vm, s)
-	defer func() {
-		_ = s.UnreadRune()
-	}()
+	s.mu.Lock()
+	pos := s.position
+	s.mu.Unlock()
 
+	p := NewParser(vm, s)
+	p.lexer.keepComments = opts.keepComments
 	t, err := p.Term()
+
+	// Give the rune looked ahead back to the stream before the continuation reads from it.
+	if _, ok := p.lexer.readAhead(); ok {
+		_ = s.UnreadRune()
+	}
 	switch err {
 	case nil:
 		break
 	case io.EOF:
-		return Unify(vm, out, atomEndOfFile, k, env)
+		t = atomEndOfFile
 	case errWrongIOMode:
 		return Error(permissionError(operationInput, permissionTypeStream, streamOrAlias, env))
 	case errWrongStreamType:
@@ -1750,13 +2526,96 @@ func ReadTerm(vm *VM, streamOrAlias, out, options Term, k Cont, env *Env) *Promi
 		return Error(syntaxError(err, env))
 	}
 
-	var singletons, variables, variableNames []Term
-	for _, v := range p.Vars {
-		if v.Count == 1 {
-			singletons = append(singletons, v.Variable)
-		}
-		variables = append(variables, v.Variable)
-		variableNames = append(variableNames, atomEqual.Apply(v.Name, v.Variable))
+	return opts.unify(vm, out, t, p, pos, k, env)
+}
+
+// ReadTermFromAtom parses the text of atom into a term with options same as ReadTerm and unifies it with out.
+// The end token after the term is optional.
+func ReadTermFromAtom(vm *VM, atom, out, options Term, k Cont, env *Env) *Promise {
+	var a Atom
+	switch atom := env.Resolve(atom).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	case Atom:
+		a = atom
+	default:
+		return Error(typeError(validTypeAtom, atom, env))
+	}
+
+	opts, err := newReadTermOptions(options, env)
+	if err != nil {
+		return Error(err)
+	}
+
+	p, t, err := parseAtom(vm, a, opts.keepComments)
+	if err != nil {
+		return Error(syntaxError(err, env))
+	}
+
+	return opts.unify(vm, out, t, p, 0, k, env)
+}
+
+// parseAtom parses the text of a into a term. The end token after the term is optional but nothing else may follow it.
+// If keepComments is true, the parser collects the comments in the text.
+func parseAtom(vm *VM, a Atom, keepComments bool) (*Parser, Term, error) {
+	p := NewParser(vm, strings.NewReader(a.String()))
+	p.lexer.keepComments = keepComments
+	t, err := p.Term()
+	if err == io.EOF {
+		// Put the end on a new line so that it won't be a part of a graphic token nor a comment.
+		p = NewParser(vm, strings.NewReader(a.String()+"\n."))
+		p.lexer.keepComments = keepComments
+		t, err = p.Term()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.More() {
+		t, _ := p.next()
+		return nil, nil, unexpectedTokenError{actual: t}
+	}
+	return p, t, nil
+}
+
+func newReadTermOptions(options Term, env *Env) (readTermOptions, error) {
+	opts := readTermOptions{
+		singletons:    NewVariable(),
+		variables:     NewVariable(),
+		variableNames: NewVariable(),
+		comments:      NewVariable(),
+	}
+	iter := ListIterator{List: options, Env: env}
+	for iter.Next() {
+		if err := readTermOption(&opts, iter.Current(), env); err != nil {
+			return opts, err
+		}
+	}
+	return opts, iter.Err()
+}
+
+// unify unifies out with t and the options with the corresponding properties of t read by p. pos is the offset of p.
+func (opts *readTermOptions) unify(vm *VM, out, t Term, p *Parser, pos int64, k Cont, env *Env) *Promise {
+	var singletons, variableNames []Term
+	for _, v := range p.Vars {
+		if v.Count == 1 {
+			singletons = append(singletons, v.Variable)
+		}
+		variableNames = append(variableNames, atomEqual.Apply(v.Name, v.Variable))
+	}
+
+	// Unlike variable_names, variables include anonymous variables.
+	variables, err := termVariables(t, env)
+	if err != nil {
+		return Error(err)
+	}
+
+	if opts.cycles {
+		t, variables, env = expandCycles(t, variables, env)
+	}
+
+	comments := make([]Term, len(p.lexer.comments))
+	for i, c := range p.lexer.comments {
+		comments[i] = atomMinus.Apply(Integer(pos+int64(c.pos)), NewAtom(c.text))
 	}
 
 	return Unify(vm, tuple(
@@ -1764,14 +2623,57 @@ func ReadTerm(vm *VM, streamOrAlias, out, options Term, k Cont, env *Env) *Promi
 		opts.singletons,
 		opts.variables,
 		opts.variableNames,
+		opts.comments,
 	), tuple(
 		t,
 		List(singletons...),
 		List(variables...),
 		List(variableNames...),
+		List(comments...),
 	), k, env)
 }
 
+// expandCycles unifies the variables of @(Template, Substitutions) with their values and returns Template.
+// Since the values may refer to the variables, the result can be a cyclic term.
+// t is returned as is if it's not in the form of @(Template, [Var = Value, ...]).
+func expandCycles(t Term, variables []Term, env *Env) (Term, []Term, *Env) {
+	c, ok := env.Resolve(t).(Compound)
+	if !ok || c.Functor() != atomAtSign || c.Arity() != 2 {
+		return t, variables, env
+	}
+
+	var (
+		bound = map[Variable]struct{}{}
+		e     = env
+		iter  = ListIterator{List: c.Arg(1), Env: env}
+	)
+	for iter.Next() {
+		s, ok := e.Resolve(iter.Current()).(Compound)
+		if !ok || s.Functor() != atomEqual || s.Arity() != 2 {
+			return t, variables, env
+		}
+		v, ok := e.Resolve(s.Arg(0)).(Variable)
+		if !ok {
+			return t, variables, env
+		}
+		bound[v] = struct{}{}
+		if e, ok = e.Unify(v, s.Arg(1)); !ok {
+			return t, variables, env
+		}
+	}
+	if iter.Err() != nil {
+		return t, variables, env
+	}
+
+	var rest []Term
+	for _, v := range variables {
+		if _, ok := bound[v.(Variable)]; !ok {
+			rest = append(rest, v)
+		}
+	}
+	return c.Arg(0), rest, e
+}
+
 func readTermOption(opts *readTermOptions, option Term, env *Env) error {
 	switch option := env.Resolve(option).(type) {
 	case Variable:
@@ -1789,6 +2691,18 @@ func readTermOption(opts *readTermOptions, option Term, env *Env) error {
 			opts.variables = v
 		case atomVariableNames:
 			opts.variableNames = v
+		case atomComments:
+			opts.comments = v
+			opts.keepComments = true
+		case atomCycles:
+			switch v {
+			case atomTrue:
+				opts.cycles = true
+			case atomFalse:
+				opts.cycles = false
+			default:
+				return domainError(validDomainReadOption, option, env)
+			}
 		default:
 			return domainError(validDomainReadOption, option, env)
 		}
@@ -1889,9 +2803,7 @@ func PeekByte(vm *VM, streamOrAlias, inByte Term, k Cont, env *Env) *Promise {
 	}
 
 	b, err := s.ReadByte()
-	defer func() {
-		_ = s.UnreadByte()
-	}()
+	_ = s.UnreadByte()
 	switch err {
 	case nil:
 		return Unify(vm, inByte, Integer(b), k, env)
@@ -1927,9 +2839,7 @@ func PeekChar(vm *VM, streamOrAlias, char Term, k Cont, env *Env) *Promise {
 	}
 
 	r, _, err := s.ReadRune()
-	defer func() {
-		_ = s.UnreadRune()
-	}()
+	_ = s.UnreadRune()
 	switch err {
 	case nil:
 		if r == unicode.ReplacementChar {
@@ -1950,21 +2860,106 @@ func PeekChar(vm *VM, streamOrAlias, char Term, k Cont, env *Env) *Promise {
 	}
 }
 
-var osExit = os.Exit
+// haltError is an error that stops the execution. It can't be caught by catch/3.
+type haltError struct {
+	code int
+}
+
+func (e haltError) Error() string {
+	return fmt.Sprintf("halt(%d)", e.code)
+}
+
+// ExitCode returns the exit code and true if err is caused by halt/1.
+func ExitCode(err error) (int, bool) {
+	var h haltError
+	if !errors.As(err, &h) {
+		return 0, false
+	}
+	return h.code, true
+}
 
-// Halt exits the process with exit code of n.
-func Halt(_ *VM, n Term, k Cont, env *Env) *Promise {
+// Halt stops the execution with exit code of n. OnHalt of the VM is called before it stops.
+func Halt(vm *VM, n Term, k Cont, env *Env) *Promise {
 	switch code := env.Resolve(n).(type) {
 	case Variable:
 		return Error(InstantiationError(env))
 	case Integer:
-		osExit(int(code))
-		return k(env)
+		if vm != nil && vm.OnHalt != nil {
+			vm.OnHalt(int(code))
+		}
+		return Error(haltError{code: int(code)})
 	default:
 		return Error(typeError(validTypeInteger, n, env))
 	}
 }
 
+// PrintMessage prints message of kind e.g. error, warning, and informational.
+// If message_hook(Message, Kind, Lines) is defined and succeeds, the message is considered to be handled and not printed.
+func PrintMessage(vm *VM, kind, message Term, k Cont, env *Env) *Promise {
+	var kd Atom
+	switch k := env.Resolve(kind).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	case Atom:
+		kd = k
+	default:
+		return Error(typeError(validTypeAtom, kind, env))
+	}
+
+	return printMessage(vm, kd, message, func(env *Env) {
+		_, _ = fmt.Fprintln(vm.userError(), messageText(vm, kd, message, env))
+	}, k, env)
+}
+
+// printMessage gives message_hook/3 a chance to handle the message. Otherwise, it calls print.
+func printMessage(vm *VM, kind Atom, message Term, print func(*Env), k Cont, env *Env) *Promise {
+	if _, ok := vm.procedures[procedureIndicator{name: atomMessageHook, arity: 3}]; !ok {
+		print(env)
+		return k(env)
+	}
+
+	lines := List(NewAtom(messageText(vm, kind, message, env)))
+
+	var p *Promise
+	p = Delay(func(context.Context) *Promise {
+		return Call(vm, atomMessageHook.Apply(message, kind, lines), func(*Env) *Promise {
+			return cut(p, func(context.Context) *Promise {
+				return k(env)
+			})
+		}, env)
+	}, func(context.Context) *Promise {
+		print(env)
+		return k(env)
+	})
+	return p
+}
+
+// userError returns the writer for user_error. It falls back to the standard error if the VM has no user_error.
+func (vm *VM) userError() io.Writer {
+	if s, ok := vm.streams.lookup(atomUserError); ok {
+		if w, err := s.textWriter(); err == nil {
+			return w
+		}
+	}
+	return os.Stderr
+}
+
+func messageText(vm *VM, kind Atom, message Term, env *Env) string {
+	var sb strings.Builder
+	switch kind {
+	case atomError:
+		_, _ = sb.WriteString("ERROR: ")
+	case atomWarning:
+		_, _ = sb.WriteString("Warning: ")
+	case atomInformational:
+		_, _ = sb.WriteString("% ")
+	}
+	opts := defaultWriteOptions.withQuoted(true)
+	opts.vm = vm
+	_ = env.Resolve(message).WriteTerm(&sb, opts, env)
+	return sb.String()
+}
+
 // Clause unifies head and body with H and B respectively where H :- B is in the database.
 func Clause(vm *VM, head, body Term, k Cont, env *Env) *Promise {
 	pi, _, err := piArg(head, env)
@@ -1989,16 +2984,20 @@ func Clause(vm *VM, head, body Term, k Cont, env *Env) *Promise {
 		return Error(permissionError(operationAccess, permissionTypePrivateProcedure, pi.Term(), env))
 	}
 
-	ks := make([]func(context.Context) *Promise, len(u.clauses))
-	for i, c := range u.clauses {
+	ks := make([]func(context.Context) *Promise, 0, len(u.clauses))
+	for _, c := range u.clauses {
+		// A rule with alternatives in its body is compiled into consecutive clauses of the same raw term.
+		if c.alternative {
+			continue
+		}
 		cp, err := renamedCopy(c.raw, nil, env)
 		if err != nil {
 			return Error(err)
 		}
 		r := rulify(cp, env)
-		ks[i] = func(context.Context) *Promise {
+		ks = append(ks, func(context.Context) *Promise {
 			return Unify(vm, atomIf.Apply(head, body), r, k, env)
-		}
+		})
 	}
 	return Delay(ks...)
 }
@@ -2111,15 +3110,28 @@ func SubAtom(vm *VM, atom, before, length, after, subAtom Term, k Cont, env *Env
 			return Error(err)
 		}
 
-		switch env.Resolve(subAtom).(type) {
-		case Variable, Atom:
+		pattern := tuple(before, length, after, subAtom)
+		var ks []func(context.Context) *Promise
+		switch sub := env.Resolve(subAtom).(type) {
+		case Variable:
 			break
+		case Atom:
+			// Only the occurrences of the known sub atom are candidates.
+			subRs := []rune(sub.String())
+			for i := 0; i+len(subRs) <= len(rs); i++ {
+				if string(rs[i:i+len(subRs)]) != sub.String() {
+					continue
+				}
+				before, length, after := Integer(i), Integer(len(subRs)), Integer(len(rs)-i-len(subRs))
+				ks = append(ks, func(context.Context) *Promise {
+					return Unify(vm, pattern, tuple(before, length, after, sub), k, env)
+				})
+			}
+			return Delay(ks...)
 		default:
 			return Error(typeError(validTypeAtom, subAtom, env))
 		}
 
-		pattern := tuple(before, length, after, subAtom)
-		var ks []func(context.Context) *Promise
 		for i := 0; i <= len(rs); i++ {
 			for j := i; j <= len(rs); j++ {
 				before, length, after, subAtom := Integer(i), Integer(j-i), Integer(len(rs)-j), NewAtom(string(rs[i:j]))
@@ -2134,6 +3146,79 @@ func SubAtom(vm *VM, atom, before, length, after, subAtom Term, k Cont, env *Env
 	}
 }
 
+// SplitString breaks str into sub atoms separated by any of the characters in sepChars, removes any of the characters in
+// padChars from both ends of each sub atom, and unifies subStrings with the list of them.
+// If sepChars is empty, str is just stripped of padChars.
+// str, sepChars, and padChars are either atoms or lists of characters or codes, e.g. double-quoted strings.
+func SplitString(vm *VM, str, sepChars, padChars, subStrings Term, k Cont, env *Env) *Promise {
+	var texts [3]string
+	for i, t := range []Term{str, sepChars, padChars} {
+		s, err := textString(t, env)
+		if err != nil {
+			return Error(err)
+		}
+		texts[i] = s
+	}
+
+	s, seps, pads := texts[0], texts[1], texts[2]
+	var subs []Term
+	start := 0
+	for i, r := range s {
+		if strings.ContainsRune(seps, r) {
+			subs = append(subs, NewAtom(strings.Trim(s[start:i], pads)))
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	subs = append(subs, NewAtom(strings.Trim(s[start:], pads)))
+	return Unify(vm, subStrings, List(subs...), k, env)
+}
+
+// textString returns the string which t represents either as an atom or as a list of characters or codes.
+// Since an empty double-quoted string is [], [] is the empty string.
+func textString(t Term, env *Env) (string, error) {
+	switch t := env.Resolve(t).(type) {
+	case Variable:
+		return "", InstantiationError(env)
+	case Atom:
+		if t == atomEmptyList {
+			return "", nil
+		}
+		return t.String(), nil
+	case charList:
+		return string(t), nil
+	case codeList:
+		return string(t), nil
+	case Compound:
+		var sb strings.Builder
+		iter := ListIterator{List: t, Env: env}
+		for iter.Next() {
+			switch e := env.Resolve(iter.Current()).(type) {
+			case Variable:
+				return "", InstantiationError(env)
+			case Atom:
+				if len([]rune(e.String())) != 1 {
+					return "", typeError(validTypeCharacter, e, env)
+				}
+				_, _ = sb.WriteString(e.String())
+			case Integer:
+				r := rune(e)
+				if !utf8.ValidRune(r) {
+					return "", representationError(flagCharacterCode, env)
+				}
+				_, _ = sb.WriteRune(r)
+			default:
+				return "", typeError(validTypeCharacter, e, env)
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	default:
+		return "", typeError(validTypeAtom, t, env)
+	}
+}
+
 func checkPositiveInteger(n Term, env *Env) error {
 	switch b := env.Resolve(n).(type) {
 	case Variable:
@@ -2149,9 +3234,14 @@ func checkPositiveInteger(n Term, env *Env) error {
 }
 
 // AtomChars breaks down atom into list of characters and unifies with chars, or constructs an atom from a list of
-// characters chars and unifies it with atom.
+// characters chars and unifies it with atom. If atom is a number, its textual representation is broken down instead.
 func AtomChars(vm *VM, atom, chars Term, k Cont, env *Env) *Promise {
-	switch a := env.Resolve(atom).(type) {
+	a := env.Resolve(atom)
+	if n, ok := a.(Number); ok {
+		a = numberAtom(n, env)
+	}
+
+	switch a := a.(type) {
 	case Variable:
 		var sb strings.Builder
 		iter := ListIterator{List: chars, Env: env}
@@ -2200,10 +3290,22 @@ func AtomChars(vm *VM, atom, chars Term, k Cont, env *Env) *Promise {
 	}
 }
 
+// numberAtom returns an atom representation of n in the same way as write/1 does.
+func numberAtom(n Number, env *Env) Atom {
+	var sb strings.Builder
+	_ = n.WriteTerm(&sb, &defaultWriteOptions, env)
+	return NewAtom(sb.String())
+}
+
 // AtomCodes breaks up atom into a list of runes and unifies it with codes, or constructs an atom from the list of runes
-// and unifies it with atom.
+// and unifies it with atom. If atom is a number, its textual representation is broken up instead.
 func AtomCodes(vm *VM, atom, codes Term, k Cont, env *Env) *Promise {
-	switch a := env.Resolve(atom).(type) {
+	a := env.Resolve(atom)
+	if n, ok := a.(Number); ok {
+		a = numberAtom(n, env)
+	}
+
+	switch a := a.(type) {
 	case Variable:
 		var sb strings.Builder
 		iter := ListIterator{List: codes, Env: env}
@@ -2417,16 +3519,82 @@ func numberCodesWrite(vm *VM, num, codes Term, k Cont, env *Env) *Promise {
 	return Unify(vm, codes, List(cs...), k, env)
 }
 
+// AtomNumber converts between atom and number. If atom is instantiated, it parses atom into a number and unifies it
+// with number. Unlike NumberCodes, it fails if atom is not a number.
+func AtomNumber(vm *VM, atom, number Term, k Cont, env *Env) *Promise {
+	switch a := env.Resolve(atom).(type) {
+	case Variable:
+		switch n := env.Resolve(number).(type) {
+		case Variable:
+			return Error(InstantiationError(env))
+		case Number:
+			var sb strings.Builder
+			_ = n.WriteTerm(&sb, &defaultWriteOptions, env)
+			return Unify(vm, atom, NewAtom(sb.String()), k, env)
+		default:
+			return Error(typeError(validTypeNumber, n, env))
+		}
+	case Atom:
+		s := a.String()
+		if strings.TrimSpace(s) != s {
+			return Bool(false)
+		}
+
+		p := Parser{
+			lexer: Lexer{
+				input: newRuneRingBuffer(strings.NewReader(s)),
+			},
+		}
+		n, err := p.number()
+		if err != nil {
+			return Bool(false)
+		}
+		return Unify(vm, number, n, k, env)
+	default:
+		return Error(typeError(validTypeAtom, atom, env))
+	}
+}
+
+// TermToAtom converts between term and atom. If atom is instantiated, it parses the text of atom into a term with the
+// current operators and unifies it with term. Otherwise, it writes term quoted into an atom.
+func TermToAtom(vm *VM, term, atom Term, k Cont, env *Env) *Promise {
+	switch a := env.Resolve(atom).(type) {
+	case Variable:
+		t := env.Resolve(term)
+		if _, ok := t.(Variable); ok {
+			return Error(InstantiationError(env))
+		}
+
+		var sb strings.Builder
+		opts := WriteOptions{
+			ops:      vm.operators,
+			quoted:   true,
+			priority: 1200,
+			vm:       vm,
+		}
+		if err := t.WriteTerm(&sb, &opts, env); err != nil {
+			return Error(err)
+		}
+		return Unify(vm, atom, NewAtom(sb.String()), k, env)
+	case Atom:
+		_, t, err := parseAtom(vm, a, false)
+		if err != nil {
+			return Error(syntaxError(err, env))
+		}
+		return Unify(vm, term, t, k, env)
+	default:
+		return Error(typeError(validTypeAtom, atom, env))
+	}
+}
+
 // StreamProperty succeeds iff the stream represented by stream has the stream property.
 func StreamProperty(vm *VM, stream, property Term, k Cont, env *Env) *Promise {
-	streams := make([]*Stream, 0, len(vm.streams.elems))
+	var streams []*Stream
 	switch s := env.Resolve(stream).(type) {
 	case Variable:
-		for _, v := range vm.streams.elems {
-			streams = append(streams, v)
-		}
+		streams = vm.streams.all()
 	case *Stream:
-		streams = append(streams, s)
+		streams = []*Stream{s}
 	default:
 		return Error(domainError(validDomainStream, stream, env))
 	}
@@ -2460,7 +3628,7 @@ func isStreamProperty(property Term, env *Env) bool {
 		}
 		arg := p.Arg(0)
 		switch p.Functor() {
-		case atomFileName, atomMode, atomAlias, atomEndOfStream, atomEOFAction, atomReposition:
+		case atomFileName, atomMode, atomAlias, atomEndOfStream, atomEOFAction, atomReposition, atomType:
 			return isAtom(arg, env)
 		case atomPosition:
 			return isInteger(arg, env)
@@ -2618,6 +3786,10 @@ func SetPrologFlag(vm *VM, flag, value Term, k Cont, env *Env) *Promise {
 			modify = modifyUnknown
 		case atomDoubleQuotes:
 			modify = modifyDoubleQuotes
+		case atomDigitSeparators:
+			modify = modifyDigitSeparators
+		case atomOccursCheck:
+			modify = modifyOccursCheck
 		default:
 			return Error(domainError(validDomainPrologFlag, f, env))
 		}
@@ -2638,6 +3810,37 @@ func SetPrologFlag(vm *VM, flag, value Term, k Cont, env *Env) *Promise {
 	}
 }
 
+// StyleCheck turns on/off the style checks. option is one of +singleton, -singleton, +discontiguous, or -discontiguous.
+func StyleCheck(vm *VM, option Term, k Cont, env *Env) *Promise {
+	switch o := env.Resolve(option).(type) {
+	case Variable:
+		return Error(InstantiationError(env))
+	case Compound:
+		if o.Arity() != 1 || (o.Functor() != atomPlus && o.Functor() != atomMinus) {
+			return Error(domainError(validDomainStyleName, option, env))
+		}
+		switch n := env.Resolve(o.Arg(0)).(type) {
+		case Variable:
+			return Error(InstantiationError(env))
+		case Atom:
+			off := o.Functor() == atomMinus
+			switch n {
+			case atomSingleton:
+				vm.noSingletonWarn = off
+			case atomDiscontiguous:
+				vm.noDiscontiguousCheck = off
+			default:
+				return Error(domainError(validDomainStyleName, n, env))
+			}
+			return k(env)
+		default:
+			return Error(typeError(validTypeAtom, n, env))
+		}
+	default:
+		return Error(domainError(validDomainStyleName, option, env))
+	}
+}
+
 func modifyCharConversion(vm *VM, value Atom) error {
 	switch value {
 	case atomOn:
@@ -2690,6 +3893,32 @@ func modifyDoubleQuotes(vm *VM, value Atom) error {
 	return nil
 }
 
+func modifyDigitSeparators(vm *VM, value Atom) error {
+	switch value {
+	case atomOn:
+		vm.digitSeparators = true
+	case atomOff:
+		vm.digitSeparators = false
+	default:
+		return domainError(validDomainFlagValue, atomPlus.Apply(atomDigitSeparators, value), nil)
+	}
+	return nil
+}
+
+func modifyOccursCheck(vm *VM, value Atom) error {
+	switch value {
+	case atomFalse:
+		vm.occursCheck = occursCheckFalse
+	case atomTrue:
+		vm.occursCheck = occursCheckTrue
+	case atomError:
+		vm.occursCheck = occursCheckError
+	default:
+		return domainError(validDomainFlagValue, atomPlus.Apply(atomOccursCheck, value), nil)
+	}
+	return nil
+}
+
 // CurrentPrologFlag succeeds iff flag is set to value.
 func CurrentPrologFlag(vm *VM, flag, value Term, k Cont, env *Env) *Promise {
 	switch f := env.Resolve(flag).(type) {
@@ -2697,7 +3926,7 @@ func CurrentPrologFlag(vm *VM, flag, value Term, k Cont, env *Env) *Promise {
 		break
 	case Atom:
 		switch f {
-		case atomBounded, atomMaxInteger, atomMinInteger, atomIntegerRoundingFunction, atomCharConversion, atomDebug, atomMaxArity, atomUnknown, atomDoubleQuotes:
+		case atomBounded, atomMaxInteger, atomMinInteger, atomIntegerRoundingFunction, atomCharConversion, atomDebug, atomMaxArity, atomUnknown, atomDoubleQuotes, atomDigitSeparators, atomOccursCheck:
 			break
 		default:
 			return Error(domainError(validDomainPrologFlag, f, env))
@@ -2717,6 +3946,8 @@ func CurrentPrologFlag(vm *VM, flag, value Term, k Cont, env *Env) *Promise {
 		tuple(atomMaxArity, atomUnbounded),
 		tuple(atomUnknown, NewAtom(vm.unknown.String())),
 		tuple(atomDoubleQuotes, NewAtom(vm.doubleQuotes.String())),
+		tuple(atomDigitSeparators, onOff(vm.digitSeparators)),
+		tuple(atomOccursCheck, NewAtom(vm.occursCheck.String())),
 	}
 	ks := make([]func(context.Context) *Promise, len(flags))
 	for i := range flags {
@@ -2782,7 +4013,7 @@ func nth(vm *VM, base Integer, n, list, elem Term, k Cont, env *Env) *Promise {
 	switch n := env.Resolve(n).(type) {
 	case Variable:
 		var ks []func(context.Context) *Promise
-		iter := ListIterator{List: list, Env: env}
+		iter := ListIterator{List: list, Env: env, AllowPartial: true}
 		for i := base; iter.Next(); i++ {
 			i, e := i, iter.Current()
 			ks = append(ks, func(context.Context) *Promise {
@@ -2797,8 +4028,9 @@ func nth(vm *VM, base Integer, n, list, elem Term, k Cont, env *Env) *Promise {
 		if n < base {
 			return Bool(false)
 		}
-		iter := ListIterator{List: list, Env: env, AllowCycle: true}
-		for i := base; iter.Next(); i++ {
+		iter := ListIterator{List: list, Env: env, AllowPartial: true, AllowCycle: true}
+		i := base
+		for ; iter.Next(); i++ {
 			if i == n {
 				return Unify(vm, elem, iter.Current(), k, env)
 			}
@@ -2806,17 +4038,80 @@ func nth(vm *VM, base Integer, n, list, elem Term, k Cont, env *Env) *Promise {
 		if err := iter.Err(); err != nil {
 			return Error(err)
 		}
-		return Bool(false)
+
+		// If list is a partial list, extend it so that elem is the nth element.
+		tail, ok := env.Resolve(iter.Suffix()).(Variable)
+		if !ok {
+			return Bool(false)
+		}
+		elems := make([]Term, n-i+1)
+		for j := range elems {
+			elems[j] = NewVariable()
+		}
+		elems[len(elems)-1] = elem
+		return Unify(vm, tail, PartialList(NewVariable(), elems...), k, env)
 	default:
 		return Error(typeError(validTypeInteger, n, env))
 	}
 }
 
+// Last succeeds if elem is the last element of list. It fails if list is a partial list.
+func Last(vm *VM, list, elem Term, k Cont, env *Env) *Promise {
+	elems, ok, err := properList(list, env)
+	if err != nil {
+		return Error(err)
+	}
+	if !ok || len(elems) == 0 {
+		return Bool(false)
+	}
+	return Unify(vm, elem, elems[len(elems)-1], k, env)
+}
+
+// Reverse succeeds if reversed is a list of the elements of list in the reverse order.
+// If list is a partial list, it reverses reversed instead. It fails if both of them are partial lists.
+func Reverse(vm *VM, list, reversed Term, k Cont, env *Env) *Promise {
+	elems, ok, err := properList(list, env)
+	if err != nil {
+		return Error(err)
+	}
+	if !ok {
+		elems, ok, err = properList(reversed, env)
+		if err != nil {
+			return Error(err)
+		}
+		if !ok {
+			return Bool(false)
+		}
+		list, reversed = reversed, list
+	}
+
+	rs := make([]Term, len(elems))
+	for i, e := range elems {
+		rs[len(elems)-1-i] = e
+	}
+	return Unify(vm, reversed, List(rs...), k, env)
+}
+
+// properList returns the elements of list. ok is false if list is a partial list.
+func properList(list Term, env *Env) (elems []Term, ok bool, err error) {
+	iter := ListIterator{List: list, Env: env, AllowPartial: true}
+	for iter.Next() {
+		elems = append(elems, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, false, err
+	}
+	if _, ok := env.Resolve(iter.Suffix()).(Variable); ok {
+		return nil, false, nil
+	}
+	return elems, true, nil
+}
+
 // Succ succeeds if s is the successor of non-negative integer x.
 func Succ(vm *VM, x, s Term, k Cont, env *Env) *Promise {
-	switch x := x.(type) {
+	switch x := env.Resolve(x).(type) {
 	case Variable:
-		switch s := s.(type) {
+		switch s := env.Resolve(s).(type) {
 		case Variable:
 			return Error(InstantiationError(env))
 		case Integer:
@@ -2845,7 +4140,7 @@ func Succ(vm *VM, x, s Term, k Cont, env *Env) *Promise {
 			return Error(err)
 		}
 
-		switch s := s.(type) {
+		switch s := env.Resolve(s).(type) {
 		case Variable:
 			return Unify(vm, s, r, k, env)
 		case Integer:
@@ -2861,6 +4156,49 @@ func Succ(vm *VM, x, s Term, k Cont, env *Env) *Promise {
 	}
 }
 
+// Plus succeeds if z is the sum of integers x and y. At least two of them have to be instantiated.
+func Plus(vm *VM, x, y, z Term, k Cont, env *Env) *Promise {
+	var ns [3]Integer
+	var unbound []int
+	for i, t := range []Term{x, y, z} {
+		switch t := env.Resolve(t).(type) {
+		case Variable:
+			unbound = append(unbound, i)
+		case Integer:
+			ns[i] = t
+		default:
+			return Error(typeError(validTypeInteger, t, env))
+		}
+	}
+
+	var (
+		r   Number
+		err error
+	)
+	switch {
+	case len(unbound) > 1:
+		return Error(InstantiationError(env))
+	case len(unbound) == 0 || unbound[0] == 2:
+		r, err = add(ns[0], ns[1])
+	case unbound[0] == 1:
+		r, err = sub(ns[2], ns[0])
+	default:
+		r, err = sub(ns[2], ns[1])
+	}
+	if err != nil {
+		var ev exceptionalValue
+		if errors.As(err, &ev) {
+			return Error(evaluationError(ev, env))
+		}
+		return Error(err)
+	}
+
+	if len(unbound) == 0 {
+		return Unify(vm, z, r, k, env)
+	}
+	return Unify(vm, []Term{x, y, z}[unbound[0]], r, k, env)
+}
+
 // Length succeeds iff list is a list of length.
 func Length(vm *VM, list, length Term, k Cont, env *Env) *Promise {
 	// https://github.com/mthom/scryer-prolog/issues/1325#issue-1160713156
@@ -413,6 +413,46 @@ func TestUnify(t *testing.T) {
 			assert.Equal(t, tt.err, err)
 		})
 	}
+
+	t.Run("occurs_check", func(t *testing.T) {
+		tests := []struct {
+			title       string
+			occursCheck occursCheck
+			ok          bool
+			err         bool
+		}{
+			{title: "false", occursCheck: occursCheckFalse, ok: true},
+			{title: "true", occursCheck: occursCheckTrue, ok: false},
+			{title: "error", occursCheck: occursCheckError, err: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				vm := VM{occursCheck: tt.occursCheck}
+				x := NewVariable()
+				f := NewAtom("f").Apply(x)
+				ok, err := Unify(&vm, x, f, Success, nil).Force(context.Background())
+				assert.Equal(t, tt.ok, ok)
+				if tt.err {
+					e, ok := err.(Exception)
+					assert.True(t, ok)
+					formal := e.Term().(Compound).Arg(0).(Compound)
+					assert.Equal(t, atomOccursCheck, formal.Functor())
+					assert.Equal(t, NewAtom("f").Apply(formal.Arg(0)), formal.Arg(1))
+				} else {
+					assert.NoError(t, err)
+				}
+
+				ok, err = Unify(&vm, x, NewAtom("f").Apply(NewAtom("a")), Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+
+				ok, err = Unify(&vm, NewAtom("a"), NewAtom("b"), Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.False(t, ok)
+			})
+		}
+	})
 }
 
 func TestUnifyWithOccursCheck(t *testing.T) {
@@ -487,6 +527,34 @@ func TestSubsumesTerm(t *testing.T) {
 		assert.NoError(t, err)
 		assert.False(t, ok)
 	})
+
+	t.Run("compound", func(t *testing.T) {
+		x, y := NewVariable(), NewVariable()
+		ok, err := SubsumesTerm(nil, NewAtom("f").Apply(x, y), NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = SubsumesTerm(nil, NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), NewAtom("f").Apply(x, y), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("shared variables", func(t *testing.T) {
+		x := NewVariable()
+		ok, err := SubsumesTerm(nil, NewAtom("f").Apply(x, x), NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("general is left unbound", func(t *testing.T) {
+		x := NewVariable()
+		ok, err := SubsumesTerm(nil, NewAtom("f").Apply(x), NewAtom("f").Apply(NewAtom("a")), func(env *Env) *Promise {
+			assert.Equal(t, x, env.Resolve(x))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
 }
 
 func TestTypeVar(t *testing.T) {
@@ -584,9 +652,116 @@ func TestAcyclicTerm(t *testing.T) {
 			assert.NoError(t, err)
 			assert.False(t, ok)
 		})
+
+		t.Run("shared subterm", func(t *testing.T) {
+			s := NewAtom("g").Apply(NewAtom("a"))
+			ok, err := AcyclicTerm(nil, NewAtom("f").Apply(s, s), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("cyclic through a binding", func(t *testing.T) {
+			x := NewVariable()
+			c := NewAtom("f").Apply(NewAtom("a"), x)
+			env := NewEnv().bind(x, NewAtom("g").Apply(c))
+			ok, err := AcyclicTerm(nil, c, Success, env).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	})
+}
+
+func BenchmarkAcyclicTerm(b *testing.B) {
+	elems := make([]Term, 20000)
+	for i := range elems {
+		elems[i] = Integer(i)
+	}
+	l := List(elems...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = AcyclicTerm(nil, l, Success, nil).Force(context.Background())
+	}
+}
+
+func TestGround(t *testing.T) {
+	t.Run("atomic", func(t *testing.T) {
+		ok, err := Ground(nil, NewAtom("a"), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("variable", func(t *testing.T) {
+		ok, err := Ground(nil, NewVariable(), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("compound", func(t *testing.T) {
+		t.Run("ground", func(t *testing.T) {
+			c := &compound{functor: NewAtom("f"), args: []Term{NewAtom("a"), &compound{functor: NewAtom("g"), args: []Term{Integer(1)}}}}
+
+			ok, err := Ground(nil, c, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.True(t, c.knownGround())
+			assert.True(t, c.args[1].(*compound).knownGround())
+
+			ok, err = Ground(nil, c, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("after binding", func(t *testing.T) {
+			x := NewVariable()
+			c := &compound{functor: NewAtom("f"), args: []Term{NewAtom("a"), x}}
+
+			ok, err := Ground(nil, c, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			env := NewEnv().bind(x, NewAtom("b"))
+			ok, err = Ground(nil, c, Success, env).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, c.knownGround())
+
+			ok, err = Ground(nil, c, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+
+		t.Run("cyclic", func(t *testing.T) {
+			x, y := NewVariable(), NewVariable()
+			c := &compound{functor: NewAtom("f"), args: []Term{x}}
+			env := NewEnv().bind(x, c)
+
+			ok, err := Ground(nil, c, Success, env).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, c.knownGround())
+
+			d := &compound{functor: NewAtom("g"), args: []Term{x, y}}
+			ok, err = Ground(nil, d, Success, env).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
 	})
 }
 
+func BenchmarkGround(b *testing.B) {
+	args := make([]Term, 10000)
+	for i := range args {
+		args[i] = &compound{functor: NewAtom("f"), args: []Term{Integer(i), NewAtom("a")}}
+	}
+	c := &compound{functor: NewAtom("g"), args: args}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Ground(nil, c, Success, nil).Force(context.Background())
+	}
+}
+
 func TestFunctor(t *testing.T) {
 	x, y := NewVariable(), NewVariable()
 	a, b := NewVariable(), NewVariable()
@@ -812,6 +987,83 @@ func TestUniv(t *testing.T) {
 	}
 }
 
+func TestVariant(t *testing.T) {
+	x, y, a, b := NewVariable(), NewVariable(), NewVariable(), NewVariable()
+	f := NewAtom("f")
+
+	tests := []struct {
+		title        string
+		term1, term2 Term
+		env          *Env
+		ok           bool
+	}{
+		{title: `f(X, Y) =@= f(A, B)`, term1: f.Apply(x, y), term2: f.Apply(a, b), ok: true},
+		{title: `f(X, X) =@= f(A, B)`, term1: f.Apply(x, x), term2: f.Apply(a, b), ok: false},
+		{title: `f(X, Y) =@= f(A, A)`, term1: f.Apply(x, y), term2: f.Apply(a, a), ok: false},
+		{title: `f(X, Y) =@= f(Y, X)`, term1: f.Apply(x, y), term2: f.Apply(y, x), ok: true},
+		{title: `f(X, '$VAR'(0)) =@= f('$VAR'(0), X)`, term1: f.Apply(x, atomVar.Apply(Integer(0))), term2: f.Apply(atomVar.Apply(Integer(0)), x), ok: false},
+		{title: `f(a, 1) =@= f(a, 1)`, term1: f.Apply(NewAtom("a"), Integer(1)), term2: f.Apply(NewAtom("a"), Integer(1)), ok: true},
+		{title: `f(a, 1) =@= f(a, 1.0)`, term1: f.Apply(NewAtom("a"), Integer(1)), term2: f.Apply(NewAtom("a"), Float(1)), ok: false},
+		{title: `a =@= X`, term1: NewAtom("a"), term2: x, ok: false},
+		{title: `X =@= a`, term1: x, term2: NewAtom("a"), ok: false},
+		{title: `f(X) =@= g(X)`, term1: f.Apply(x), term2: NewAtom("g").Apply(x), ok: false},
+		{title: `[X, Y] =@= [A, B]`, term1: List(x, y), term2: List(a, b), ok: true},
+		{title: `f(X) =@= f(a), X = a`, term1: f.Apply(x), term2: f.Apply(NewAtom("a")), env: NewEnv().bind(x, NewAtom("a")), ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := Variant(nil, tt.term1, tt.term2, func(env *Env) *Promise {
+				assert.Equal(t, tt.env, env)
+				return Bool(true)
+			}, tt.env).Force(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestRenameTerm(t *testing.T) {
+	x, y, a, b := NewVariable(), NewVariable(), NewVariable(), NewVariable()
+	f := NewAtom("f")
+
+	rename := func(t Term, env *Env) Term {
+		r := NewVariable()
+		var ret Term
+		_, _ = RenameTerm(nil, t, r, func(env *Env) *Promise {
+			ret = env.simplify(r)
+			return Bool(true)
+		}, env).Force(context.Background())
+		return ret
+	}
+
+	assert.Equal(t, f.Apply(atomVar.Apply(Integer(0)), atomVar.Apply(Integer(1))), rename(f.Apply(x, y), nil))
+	assert.Equal(t, rename(f.Apply(x, y), nil), rename(f.Apply(a, b), nil))
+	assert.Equal(t, f.Apply(atomVar.Apply(Integer(0)), atomVar.Apply(Integer(0))), rename(f.Apply(x, x), nil))
+	assert.NotEqual(t, rename(f.Apply(x, y), nil), rename(f.Apply(x, x), nil))
+	assert.Equal(t, List(NewAtom("a"), atomVar.Apply(Integer(0))), rename(List(x, y), NewEnv().bind(x, NewAtom("a"))))
+
+	// The original variables are intact.
+	ok, err := RenameTerm(nil, f.Apply(x, y), NewVariable(), func(env *Env) *Promise {
+		assert.Equal(t, x, env.Resolve(x))
+		assert.Equal(t, y, env.Resolve(y))
+		return Bool(true)
+	}, nil).Force(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	t.Run("numbered variable", func(t *testing.T) {
+		n := atomVar.Apply(Integer(0))
+		ok, err := RenameTerm(nil, f.Apply(n, x), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeVariable, n, nil), err)
+		assert.False(t, ok)
+
+		ok, err = RenameTerm(nil, f.Apply(atomVar.Apply(NewAtom("X")), x), NewVariable(), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
 func TestCopyTerm(t *testing.T) {
 	x, y := NewVariable(), NewVariable()
 	a, b := NewVariable(), NewVariable()
@@ -838,6 +1090,12 @@ func TestCopyTerm(t *testing.T) {
 			y: NewAtom("f").Apply(y),
 		}},
 
+		{title: "copy_term(f(X, Y, X), f(a, A, B)).", in: NewAtom("f").Apply(x, y, x), out: NewAtom("f").Apply(NewAtom("a"), a, b), ok: true, env: map[Variable]Term{
+			x: x,
+			y: y,
+			b: NewAtom("a"),
+		}},
+
 		{title: "charList", in: CharList("foo"), out: CharList("foo"), ok: true},
 		{title: "codeList", in: CodeList("foo"), out: CodeList("foo"), ok: true},
 		{title: "list", in: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), out: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ok: true},
@@ -2073,6 +2331,60 @@ func TestFindAll(t *testing.T) {
 	}
 }
 
+func TestFindAll4(t *testing.T) {
+	x := NewVariable()
+
+	var vm VM
+	vm.Register2(atomEqual, Unify)
+	vm.Register2(atomSemiColon, func(vm *VM, g1, g2 Term, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return Call(vm, g1, k, env)
+		}, func(context.Context) *Promise {
+			return Call(vm, g2, k, env)
+		})
+	})
+	vm.Register0(atomFail, func(*VM, Cont, *Env) *Promise {
+		return Bool(false)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		l := NewVariable()
+		ok, err := FindAll4(&vm, x, atomSemiColon.Apply(atomEqual.Apply(x, Integer(1)), atomEqual.Apply(x, Integer(2))), l, List(NewAtom("end")), func(env *Env) *Promise {
+			_, ok := env.Unify(l, List(Integer(1), Integer(2), NewAtom("end")))
+			assert.True(t, ok)
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("open tail", func(t *testing.T) {
+		l, tail := NewVariable(), NewVariable()
+		ok, err := FindAll4(&vm, x, atomEqual.Apply(x, Integer(1)), l, tail, func(env *Env) *Promise {
+			assert.Equal(t, PartialList(tail, Integer(1)), env.Resolve(l))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("no solutions", func(t *testing.T) {
+		l, tail := NewVariable(), NewVariable()
+		ok, err := FindAll4(&vm, x, atomFail, l, tail, func(env *Env) *Promise {
+			assert.Equal(t, tail, env.Resolve(l))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("instances is not a list", func(t *testing.T) {
+		ok, err := FindAll4(&vm, x, atomFail, NewAtom("foo"), List(), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeList, NewAtom("foo"), nil), err)
+		assert.False(t, ok)
+	})
+}
+
 func TestCompare(t *testing.T) {
 	order := NewVariable()
 
@@ -2110,6 +2422,51 @@ func TestCompare(t *testing.T) {
 		assert.Equal(t, tt.ok, ok)
 		assert.Equal(t, tt.err, err)
 	}
+
+	t.Run("standard order", func(t *testing.T) {
+		x, y := NewVariable(), NewVariable()
+		ordered := []Term{
+			x,
+			y,
+			Float(-1),
+			Integer(0),
+			Float(1),
+			Integer(1),
+			Float(1.5),
+			Integer(2),
+			NewAtom("a"),
+			NewAtom("b"),
+			NewAtom("z").Apply(NewAtom("a")),
+			NewAtom("a").Apply(NewAtom("a"), NewAtom("a")),
+			NewAtom("a").Apply(NewAtom("b"), NewAtom("a")),
+			NewAtom("b").Apply(NewAtom("a"), NewAtom("a")),
+		}
+		for i := range ordered {
+			for j := range ordered {
+				var o Atom
+				switch {
+				case i < j:
+					o = atomLessThan
+				case i > j:
+					o = atomGreaterThan
+				default:
+					o = atomEqual
+				}
+				ok, err := Compare(nil, o, ordered[i], ordered[j], Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok, "compare(%s, %s, %s)", o, ordered[i], ordered[j])
+			}
+		}
+
+		sorted := NewVariable()
+		mixed := List(ordered[13], ordered[8], ordered[5], ordered[0], ordered[11], ordered[4], ordered[2], ordered[10], ordered[7], ordered[3], ordered[1], ordered[12], ordered[6], ordered[9])
+		ok, err := MSort(nil, mixed, sorted, func(env *Env) *Promise {
+			assert.Equal(t, List(ordered...), env.Resolve(sorted))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
 }
 
 func TestBetween(t *testing.T) {
@@ -2248,6 +2605,22 @@ func TestSort(t *testing.T) {
 			assert.NoError(t, err)
 			assert.True(t, ok)
 		})
+
+		t.Run("variables and atoms", func(t *testing.T) {
+			x, y := NewVariable(), NewVariable()
+			for _, list := range []Term{
+				List(NewAtom("b"), x, NewAtom("a"), y),
+				List(y, NewAtom("b"), x, NewAtom("a"), y, x),
+			} {
+				sorted := NewVariable()
+				ok, err := Sort(nil, list, sorted, func(env *Env) *Promise {
+					assert.Equal(t, List(x, y, NewAtom("a"), NewAtom("b")), env.Resolve(sorted))
+					return Bool(true)
+				}, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			}
+		})
 	})
 
 	t.Run("list is a partial list", func(t *testing.T) {
@@ -2273,51 +2646,183 @@ func TestSort(t *testing.T) {
 	})
 }
 
-func TestKeySort(t *testing.T) {
-	t.Run("ok", func(t *testing.T) {
-		t.Run("variable", func(t *testing.T) {
+func TestMSort(t *testing.T) {
+	// [a] as a compound and as a list are equal in the standard order but distinguishable in Go.
+	c, l := Cons(NewAtom("a"), atomEmptyList), List(NewAtom("a"))
+
+	tests := []struct {
+		title        string
+		list, sorted Term
+		err          error
+	}{
+		{title: "ok", list: List(NewAtom("c"), NewAtom("a"), NewAtom("b"), NewAtom("a")), sorted: list{NewAtom("a"), NewAtom("a"), NewAtom("b"), NewAtom("c")}},
+		{title: "stable", list: List(l, NewAtom("b"), c, l), sorted: list{NewAtom("b"), l, c, l}},
+		{title: "list is a partial list", list: PartialList(NewVariable(), NewAtom("a"), NewAtom("b")), err: InstantiationError(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
 			sorted := NewVariable()
-			ok, err := KeySort(nil, List(
-				pair(NewAtom("c"), NewAtom("4")),
-				pair(NewAtom("b"), NewAtom("3")),
-				pair(NewAtom("a"), NewAtom("1")),
-				pair(NewAtom("a"), NewAtom("2")),
-			), sorted, func(env *Env) *Promise {
-				assert.Equal(t, List(
-					pair(NewAtom("a"), NewAtom("1")),
-					pair(NewAtom("a"), NewAtom("2")),
-					pair(NewAtom("b"), NewAtom("3")),
-					pair(NewAtom("c"), NewAtom("4")),
-				), env.Resolve(sorted))
+			ok, err := MSort(nil, tt.list, sorted, func(env *Env) *Promise {
+				assert.Equal(t, tt.sorted, env.Resolve(sorted))
 				return Bool(true)
 			}, nil).Force(context.Background())
-			assert.NoError(t, err)
-			assert.True(t, ok)
+			assert.Equal(t, tt.err == nil, ok)
+			assert.Equal(t, tt.err, err)
 		})
+	}
 
-		t.Run("list", func(t *testing.T) {
-			second := NewVariable()
-			ok, err := KeySort(nil, List(
-				pair(NewAtom("c"), NewAtom("4")),
-				pair(NewAtom("b"), NewAtom("3")),
-				pair(NewAtom("a"), NewAtom("1")),
-				pair(NewAtom("a"), NewAtom("2")),
-			), List(
-				pair(NewAtom("a"), NewAtom("1")),
-				second,
-				pair(NewAtom("b"), NewAtom("3")),
-				pair(NewAtom("c"), NewAtom("4")),
-			), func(env *Env) *Promise {
-				assert.Equal(t, pair(NewAtom("a"), NewAtom("2")), env.Resolve(second))
+	t.Run("sorted is neither a partial list nor a list", func(t *testing.T) {
+		ok, err := MSort(nil, List(NewAtom("a")), NewAtom("a"), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeList, NewAtom("a"), nil), err)
+		assert.False(t, ok)
+	})
+}
+
+func TestSort4(t *testing.T) {
+	f := NewAtom("f")
+	list := List(f.Apply(Integer(2), NewAtom("a")), f.Apply(Integer(1), NewAtom("b")), f.Apply(Integer(2), NewAtom("c")), f.Apply(Integer(1), NewAtom("b")))
+
+	tests := []struct {
+		title      string
+		key, order Term
+		sorted     Term
+		err        error
+	}{
+		{title: "@<", key: Integer(1), order: atomAtLessThan, sorted: List(f.Apply(Integer(1), NewAtom("b")), f.Apply(Integer(2), NewAtom("a")))},
+		{title: "@=<", key: Integer(1), order: atomAtLessThanOrEqual, sorted: List(f.Apply(Integer(1), NewAtom("b")), f.Apply(Integer(1), NewAtom("b")), f.Apply(Integer(2), NewAtom("a")), f.Apply(Integer(2), NewAtom("c")))},
+		{title: "@>", key: Integer(1), order: atomAtGreaterThan, sorted: List(f.Apply(Integer(2), NewAtom("a")), f.Apply(Integer(1), NewAtom("b")))},
+		{title: "@>=", key: Integer(1), order: atomAtGreaterOrEqual, sorted: List(f.Apply(Integer(2), NewAtom("a")), f.Apply(Integer(2), NewAtom("c")), f.Apply(Integer(1), NewAtom("b")), f.Apply(Integer(1), NewAtom("b")))},
+		{title: "whole term", key: Integer(0), order: atomAtGreaterThan, sorted: List(f.Apply(Integer(2), NewAtom("c")), f.Apply(Integer(2), NewAtom("a")), f.Apply(Integer(1), NewAtom("b")))},
+		{title: "key is a variable", key: NewVariable(), order: atomAtLessThan, err: InstantiationError(nil)},
+		{title: "key is not an integer", key: NewAtom("a"), order: atomAtLessThan, err: typeError(validTypeInteger, NewAtom("a"), nil)},
+		{title: "key is negative", key: Integer(-1), order: atomAtLessThan, err: domainError(validDomainNotLessThanZero, Integer(-1), nil)},
+		{title: "order is a variable", key: Integer(1), order: NewVariable(), err: InstantiationError(nil)},
+		{title: "order is not an atom", key: Integer(1), order: Integer(0), err: typeError(validTypeAtom, Integer(0), nil)},
+		{title: "order is not an order", key: Integer(1), order: NewAtom("foo"), err: domainError(validDomainOrder, NewAtom("foo"), nil)},
+		{title: "element lacks the argument", key: Integer(3), order: atomAtLessThan, err: typeError(validTypeCompound, f.Apply(Integer(2), NewAtom("a")), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			sorted := NewVariable()
+			ok, err := Sort4(nil, tt.key, tt.order, list, sorted, func(env *Env) *Promise {
+				assert.Equal(t, tt.sorted, env.Resolve(sorted))
 				return Bool(true)
 			}, nil).Force(context.Background())
-			assert.NoError(t, err)
-			assert.True(t, ok)
+			assert.Equal(t, tt.err == nil, ok)
+			assert.Equal(t, tt.err, err)
 		})
-	})
+	}
 
-	t.Run("pairs is a partial list", func(t *testing.T) {
-		_, err := KeySort(nil, PartialList(NewVariable(), pair(NewAtom("a"), Integer(1))), NewVariable(), Success, nil).Force(context.Background())
+	t.Run("element is a variable", func(t *testing.T) {
+		ok, err := Sort4(nil, Integer(1), atomAtLessThan, List(f.Apply(Integer(1)), NewVariable()), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+}
+
+func TestPredSort(t *testing.T) {
+	pair := atomMinus
+	list := List(pair.Apply(Integer(2), NewAtom("a")), pair.Apply(Integer(1), NewAtom("b")), pair.Apply(Integer(2), NewAtom("c")), pair.Apply(Integer(3), NewAtom("d")))
+
+	var vm VM
+	vm.Register3(NewAtom("by_key"), func(vm *VM, order, a, b Term, k Cont, env *Env) *Promise {
+		ka, kb := env.Resolve(a).(Compound).Arg(0), env.Resolve(b).(Compound).Arg(0)
+		return Compare(vm, order, ka, kb, k, env)
+	})
+	vm.Register3(NewAtom("by_key_dup"), func(vm *VM, order, a, b Term, k Cont, env *Env) *Promise {
+		ka, kb := env.Resolve(a).(Compound).Arg(0), env.Resolve(b).(Compound).Arg(0)
+		if ka.Compare(kb, env) > 0 {
+			return Unify(vm, order, atomGreaterThan, k, env)
+		}
+		return Unify(vm, order, atomLessThan, k, env)
+	})
+	vm.Register3(NewAtom("never"), func(*VM, Term, Term, Term, Cont, *Env) *Promise {
+		return Bool(false)
+	})
+	vm.Register3(NewAtom("invalid"), func(vm *VM, order, _, _ Term, k Cont, env *Env) *Promise {
+		return Unify(vm, order, NewAtom("foo"), k, env)
+	})
+	vm.Register3(NewAtom("unbound"), func(_ *VM, _, _, _ Term, k Cont, env *Env) *Promise {
+		return k(env)
+	})
+
+	tests := []struct {
+		title  string
+		pred   Term
+		list   Term
+		ok     bool
+		sorted Term
+		err    error
+	}{
+		{title: "equal elements are removed", pred: NewAtom("by_key"), list: list, ok: true, sorted: List(pair.Apply(Integer(1), NewAtom("b")), pair.Apply(Integer(2), NewAtom("a")), pair.Apply(Integer(3), NewAtom("d")))},
+		{title: "stable", pred: NewAtom("by_key_dup"), list: list, ok: true, sorted: List(pair.Apply(Integer(1), NewAtom("b")), pair.Apply(Integer(2), NewAtom("a")), pair.Apply(Integer(2), NewAtom("c")), pair.Apply(Integer(3), NewAtom("d")))},
+		{title: "empty", pred: NewAtom("never"), list: List(), ok: true, sorted: List()},
+		{title: "pred fails", pred: NewAtom("never"), list: list, ok: false},
+		{title: "list is a partial list", pred: NewAtom("by_key"), list: PartialList(NewVariable(), NewAtom("a")), err: InstantiationError(nil)},
+		{title: "invalid order", pred: NewAtom("invalid"), list: list, err: domainError(validDomainOrder, NewAtom("foo"), nil)},
+		{title: "order is a variable", pred: NewAtom("unbound"), list: list, err: InstantiationError(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			sorted := NewVariable()
+			ok, err := PredSort(&vm, tt.pred, tt.list, sorted, func(env *Env) *Promise {
+				assert.Equal(t, tt.sorted, env.Resolve(sorted))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestKeySort(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		t.Run("variable", func(t *testing.T) {
+			sorted := NewVariable()
+			ok, err := KeySort(nil, List(
+				pair(NewAtom("c"), NewAtom("4")),
+				pair(NewAtom("b"), NewAtom("3")),
+				pair(NewAtom("a"), NewAtom("1")),
+				pair(NewAtom("a"), NewAtom("2")),
+			), sorted, func(env *Env) *Promise {
+				assert.Equal(t, List(
+					pair(NewAtom("a"), NewAtom("1")),
+					pair(NewAtom("a"), NewAtom("2")),
+					pair(NewAtom("b"), NewAtom("3")),
+					pair(NewAtom("c"), NewAtom("4")),
+				), env.Resolve(sorted))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("list", func(t *testing.T) {
+			second := NewVariable()
+			ok, err := KeySort(nil, List(
+				pair(NewAtom("c"), NewAtom("4")),
+				pair(NewAtom("b"), NewAtom("3")),
+				pair(NewAtom("a"), NewAtom("1")),
+				pair(NewAtom("a"), NewAtom("2")),
+			), List(
+				pair(NewAtom("a"), NewAtom("1")),
+				second,
+				pair(NewAtom("b"), NewAtom("3")),
+				pair(NewAtom("c"), NewAtom("4")),
+			), func(env *Env) *Promise {
+				assert.Equal(t, pair(NewAtom("a"), NewAtom("2")), env.Resolve(second))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+	})
+
+	t.Run("pairs is a partial list", func(t *testing.T) {
+		_, err := KeySort(nil, PartialList(NewVariable(), pair(NewAtom("a"), Integer(1))), NewVariable(), Success, nil).Force(context.Background())
 		assert.Equal(t, InstantiationError(nil), err)
 	})
 
@@ -2429,6 +2934,159 @@ func TestCatch(t *testing.T) {
 		assert.Error(t, err)
 		assert.False(t, ok)
 	})
+
+	t.Run("system error", func(t *testing.T) {
+		vm.Register0(NewAtom("go_error"), func(*VM, Cont, *Env) *Promise {
+			return Error(errors.New("failed"))
+		})
+
+		m := NewVariable()
+		ok, err := Catch(&vm, NewAtom("go_error"), atomError.Apply(NewAtom("system_error"), m), atomTrue, func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("failed"), env.Resolve(m))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("ball is copied", func(t *testing.T) {
+		x, y := NewVariable(), NewVariable()
+		ok, err := Catch(&vm, atomComma.Apply(
+			atomEqual.Apply(x, NewAtom("a")),
+			NewAtom("throw").Apply(NewAtom("f").Apply(x, NewVariable())),
+		), NewAtom("f").Apply(y, NewVariable()), atomTrue, func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("a"), env.Resolve(y))
+			assert.Equal(t, x, env.Resolve(x))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestCallWithInferenceLimit(t *testing.T) {
+	var vm VM
+	vm.Register0(atomTrue, func(_ *VM, k Cont, env *Env) *Promise {
+		return k(env)
+	})
+	vm.Register0(NewAtom("loop"), func(vm *VM, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return vm.Arrive(NewAtom("loop"), nil, k, env)
+		})
+	})
+
+	vm.Register0(NewAtom("twice"), func(_ *VM, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return k(env)
+		}, func(context.Context) *Promise {
+			return k(env)
+		})
+	})
+
+	t.Run("!", func(t *testing.T) {
+		r := NewVariable()
+		ok, err := CallWithInferenceLimit(&vm, atomTrue, Integer(1), r, func(env *Env) *Promise {
+			assert.Equal(t, atomCut, env.Resolve(r))
+			_, ok := env.inferenceCounter()
+			assert.False(t, ok)
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("true", func(t *testing.T) {
+		var rs []Term
+		r := NewVariable()
+		ok, err := CallWithInferenceLimit(&vm, NewAtom("twice"), Integer(1), r, func(env *Env) *Promise {
+			rs = append(rs, env.Resolve(r))
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, []Term{atomTrue, atomCut}, rs)
+	})
+
+	t.Run("inference_limit_exceeded", func(t *testing.T) {
+		r := NewVariable()
+		ok, err := CallWithInferenceLimit(&vm, NewAtom("loop"), Integer(100), r, func(env *Env) *Promise {
+			assert.Equal(t, atomInferenceLimitExceeded, env.Resolve(r))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("catch and retry", func(t *testing.T) {
+		// evade :- catch(loop, _, true), evade.
+		var vm VM
+		vm.Register0(atomTrue, func(_ *VM, k Cont, env *Env) *Promise {
+			return k(env)
+		})
+		vm.Register0(NewAtom("loop"), func(vm *VM, k Cont, env *Env) *Promise {
+			return Delay(func(context.Context) *Promise {
+				return vm.Arrive(NewAtom("loop"), nil, k, env)
+			})
+		})
+		vm.Register0(NewAtom("evade"), func(vm *VM, k Cont, env *Env) *Promise {
+			return Catch(vm, NewAtom("loop"), NewVariable(), atomTrue, func(env *Env) *Promise {
+				return vm.Arrive(NewAtom("evade"), nil, k, env)
+			}, env)
+		})
+
+		r := NewVariable()
+		ok, err := CallWithInferenceLimit(&vm, NewAtom("evade"), Integer(100), r, func(env *Env) *Promise {
+			assert.Equal(t, atomInferenceLimitExceeded, env.Resolve(r))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("outer limit exceeded", func(t *testing.T) {
+		r := NewVariable()
+		env, _ := NewEnv().limitInferences(10)
+		ok, err := CallWithInferenceLimit(&vm, NewAtom("loop"), Integer(100), r, Success, env).Force(context.Background())
+		assert.Equal(t, resourceError(resourceInferenceLimitExceeded, env.bind(varContext, procedureIndicator{name: NewAtom("loop"), arity: 0}.Term())), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("remaining", func(t *testing.T) {
+		r, n := NewVariable(), NewVariable()
+		ok, err := CallWithInferenceLimit4(&vm, atomTrue, Integer(10), r, n, func(env *Env) *Promise {
+			assert.Equal(t, atomCut, env.Resolve(r))
+			assert.Equal(t, Integer(9), env.Resolve(n))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = CallWithInferenceLimit4(&vm, NewAtom("loop"), Integer(10), r, n, func(env *Env) *Promise {
+			assert.Equal(t, atomInferenceLimitExceeded, env.Resolve(r))
+			assert.Equal(t, Integer(0), env.Resolve(n))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("limit is a variable", func(t *testing.T) {
+		ok, err := CallWithInferenceLimit(&vm, atomTrue, NewVariable(), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("limit is negative", func(t *testing.T) {
+		ok, err := CallWithInferenceLimit(&vm, atomTrue, Integer(-1), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, domainError(validDomainNotLessThanZero, Integer(-1), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("limit is not an integer", func(t *testing.T) {
+		ok, err := CallWithInferenceLimit(&vm, atomTrue, NewAtom("foo"), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeInteger, NewAtom("foo"), nil), err)
+		assert.False(t, ok)
+	})
 }
 
 func TestCurrentPredicate(t *testing.T) {
@@ -2550,6 +3208,30 @@ func TestCurrentPredicate(t *testing.T) {
 }
 
 func TestAssertz(t *testing.T) {
+	t.Run("goal order", func(t *testing.T) {
+		var (
+			vm    VM
+			trace []string
+		)
+		for _, g := range []string{"g1", "g2", "g3"} {
+			g := g
+			vm.Register1(NewAtom(g), func(_ *VM, x Term, k Cont, env *Env) *Promise {
+				trace = append(trace, fmt.Sprintf("%s(%s)", g, env.Resolve(x)))
+				return k(env)
+			})
+		}
+
+		x := NewVariable()
+		ok, err := Assertz(&vm, Rule(NewAtom("foo").Apply(x), NewAtom("g1").Apply(x), NewAtom("g2").Apply(x), NewAtom("g3").Apply(x)), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = Call(&vm, NewAtom("foo").Apply(NewAtom("a")), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"g1(a)", "g2(a)", "g3(a)"}, trace)
+	})
+
 	t.Run("append", func(t *testing.T) {
 		var vm VM
 
@@ -2661,6 +3343,37 @@ func TestAssertz(t *testing.T) {
 			},
 		}, nil), err)
 		assert.False(t, ok)
+
+		_, ok = vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 0}]
+		assert.False(t, ok)
+	})
+
+	t.Run("cyclic", func(t *testing.T) {
+		var vm VM
+		x := NewVariable()
+
+		env := NewEnv().bind(x, atomComma.Apply(atomTrue, x))
+		ok, err := Assertz(&vm, atomIf.Apply(NewAtom("foo"), x), Success, env).Force(context.Background())
+		assert.Equal(t, representationError(flagCyclicTerm, nil), err)
+		assert.False(t, ok)
+
+		env = NewEnv().bind(x, NewAtom("f").Apply(x))
+		ok, err = Assertz(&vm, NewAtom("foo").Apply(x), Success, env).Force(context.Background())
+		assert.Equal(t, representationError(flagCyclicTerm, nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("copied", func(t *testing.T) {
+		var vm VM
+		x := NewVariable()
+
+		env := NewEnv().bind(x, NewAtom("a"))
+		ok, err := Assertz(&vm, NewAtom("foo").Apply(x), Success, env).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		u := vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined)
+		assert.Equal(t, NewAtom("foo").Apply(NewAtom("a")), u.clauses[0].raw)
 	})
 
 	t.Run("static", func(t *testing.T) {
@@ -2680,6 +3393,18 @@ func TestAssertz(t *testing.T) {
 		}, nil), err)
 		assert.False(t, ok)
 	})
+
+	t.Run("builtin", func(t *testing.T) {
+		var vm VM
+		vm.Register3(NewAtom("append"), Append)
+
+		ok, err := Assertz(&vm, NewAtom("append").Apply(NewAtom("a"), NewAtom("b"), NewAtom("c")), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, atomSlash.Apply(NewAtom("append"), Integer(3)), nil), err)
+		assert.False(t, ok)
+
+		_, ok = vm.procedures[procedureIndicator{name: NewAtom("append"), arity: 3}].(Predicate3)
+		assert.True(t, ok)
+	})
 }
 
 func TestAsserta(t *testing.T) {
@@ -2966,86 +3691,274 @@ func TestRetract(t *testing.T) {
 		}}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}])
 	})
 
-	t.Run("retract all", func(t *testing.T) {
+	t.Run("retract all", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true, clauses: []clause{
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("b")}}},
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("c")}}},
+				}},
+			},
+		}
+
+		ok, err := Retract(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewVariable()},
+		}, Failure, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Empty(t, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined).clauses)
+	})
+
+	t.Run("variable", func(t *testing.T) {
+		var vm VM
+		ok, err := Retract(&vm, NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("not callable", func(t *testing.T) {
+		var vm VM
+		ok, err := Retract(&vm, Integer(0), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeCallable, Integer(0), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("no clause matches", func(t *testing.T) {
+		var vm VM
+
+		ok, err := Retract(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewVariable()},
+		}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 0}: &userDefined{dynamic: false},
+			},
+		}
+
+		ok, err := Retract(&vm, NewAtom("foo"), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, &compound{
+			functor: atomSlash,
+			args:    []Term{NewAtom("foo"), Integer(0)},
+		}, nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("exception in continuation", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true, clauses: []clause{
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
+				}},
+			},
+		}
+
+		ok, err := Retract(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewVariable()},
+		}, func(_ *Env) *Promise {
+			return Error(errors.New("failed"))
+		}, nil).Force(context.Background())
+		assert.Error(t, err)
+		assert.False(t, ok)
+
+		// removed
+		assert.Empty(t, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined).clauses)
+	})
+}
+
+func TestRetractAll(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 2}: &userDefined{dynamic: true, clauses: []clause{
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a"), Integer(1)}}},
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("b"), Integer(2)}}},
+					{raw: &compound{functor: atomIf, args: []Term{
+						&compound{functor: NewAtom("foo"), args: []Term{NewAtom("a"), Integer(3)}},
+						atomTrue,
+					}}},
+				}},
+			},
+		}
+
+		ok, err := RetractAll(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewAtom("a"), NewVariable()},
+		}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, &userDefined{dynamic: true, clauses: []clause{
+			{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("b"), Integer(2)}}},
+		}}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 2}])
+	})
+
+	t.Run("no clauses match", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true, clauses: []clause{
+					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
+				}},
+			},
+		}
+
+		ok, err := RetractAll(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewAtom("b")},
+		}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, &userDefined{dynamic: true, clauses: []clause{
+			{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
+		}}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}])
+	})
+
+	t.Run("procedure not found", func(t *testing.T) {
+		var vm VM
+		ok, err := RetractAll(&vm, &compound{
+			functor: NewAtom("foo"),
+			args:    []Term{NewVariable()},
+		}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, &userDefined{dynamic: true}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}])
+	})
+
+	t.Run("head is a variable", func(t *testing.T) {
+		var vm VM
+		ok, err := RetractAll(&vm, NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("head is neither a variable nor a callable term", func(t *testing.T) {
+		var vm VM
+		ok, err := RetractAll(&vm, Integer(0), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeCallable, Integer(0), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 0}: &userDefined{dynamic: false},
+			},
+		}
+
+		ok, err := RetractAll(&vm, NewAtom("foo"), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, &compound{
+			functor: atomSlash,
+			args:    []Term{NewAtom("foo"), Integer(0)},
+		}, nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 0}: Predicate0(func(_ *VM, k Cont, env *Env) *Promise {
+					return k(env)
+				}),
+			},
+		}
+
+		ok, err := RetractAll(&vm, NewAtom("foo"), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, &compound{
+			functor: atomSlash,
+			args:    []Term{NewAtom("foo"), Integer(0)},
+		}, nil), err)
+		assert.False(t, ok)
+	})
+}
+
+func TestDynamic(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
 		vm := VM{
 			procedures: map[procedureIndicator]procedure{
-				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true, clauses: []clause{
-					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
-					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("b")}}},
-					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("c")}}},
+				{name: NewAtom("bar"), arity: 2}: &userDefined{clauses: []clause{
+					{raw: &compound{functor: NewAtom("bar"), args: []Term{NewAtom("a"), NewAtom("b")}}},
 				}},
 			},
 		}
+		ok, err := Dynamic(&vm, atomComma.Apply(
+			atomSlash.Apply(NewAtom("foo"), Integer(1)),
+			atomSlash.Apply(NewAtom("bar"), Integer(2)),
+		), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
 
-		ok, err := Retract(&vm, &compound{
-			functor: NewAtom("foo"),
-			args:    []Term{NewVariable()},
-		}, Failure, nil).Force(context.Background())
+		assert.Equal(t, &userDefined{dynamic: true, public: true}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}])
+		assert.Equal(t, &userDefined{dynamic: true, public: true, clauses: []clause{
+			{raw: &compound{functor: NewAtom("bar"), args: []Term{NewAtom("a"), NewAtom("b")}}},
+		}}, vm.procedures[procedureIndicator{name: NewAtom("bar"), arity: 2}])
+
+		ok, err = Call(&vm, NewAtom("foo").Apply(NewVariable()), Success, nil).Force(context.Background())
 		assert.NoError(t, err)
 		assert.False(t, ok)
-		assert.Empty(t, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined).clauses)
 	})
 
-	t.Run("variable", func(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
 		var vm VM
-		ok, err := Retract(&vm, NewVariable(), Success, nil).Force(context.Background())
+		ok, err := Dynamic(&vm, List(
+			atomSlash.Apply(NewAtom("foo"), Integer(1)),
+			atomSlash.Apply(NewAtom("bar"), Integer(2)),
+		), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, &userDefined{dynamic: true, public: true}, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}])
+		assert.Equal(t, &userDefined{dynamic: true, public: true}, vm.procedures[procedureIndicator{name: NewAtom("bar"), arity: 2}])
+	})
+
+	t.Run("pi is a variable", func(t *testing.T) {
+		var vm VM
+		ok, err := Dynamic(&vm, NewVariable(), Success, nil).Force(context.Background())
 		assert.Equal(t, InstantiationError(nil), err)
 		assert.False(t, ok)
 	})
 
-	t.Run("not callable", func(t *testing.T) {
+	t.Run("pi is neither a variable nor a predicate indicator", func(t *testing.T) {
 		var vm VM
-		ok, err := Retract(&vm, Integer(0), Success, nil).Force(context.Background())
-		assert.Equal(t, typeError(validTypeCallable, Integer(0), nil), err)
+		ok, err := Dynamic(&vm, Integer(0), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypePredicateIndicator, Integer(0), nil), err)
 		assert.False(t, ok)
 	})
 
-	t.Run("no clause matches", func(t *testing.T) {
+	t.Run("pi is a term Name/Arity and Name is neither a variable nor an atom", func(t *testing.T) {
 		var vm VM
-
-		ok, err := Retract(&vm, &compound{
-			functor: NewAtom("foo"),
-			args:    []Term{NewVariable()},
-		}, Success, nil).Force(context.Background())
-		assert.NoError(t, err)
+		ok, err := Dynamic(&vm, atomSlash.Apply(Integer(0), Integer(1)), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeAtom, Integer(0), nil), err)
 		assert.False(t, ok)
 	})
 
-	t.Run("static", func(t *testing.T) {
-		vm := VM{
-			procedures: map[procedureIndicator]procedure{
-				{name: NewAtom("foo"), arity: 0}: &userDefined{dynamic: false},
-			},
-		}
-
-		ok, err := Retract(&vm, NewAtom("foo"), Success, nil).Force(context.Background())
-		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, &compound{
-			functor: atomSlash,
-			args:    []Term{NewAtom("foo"), Integer(0)},
-		}, nil), err)
+	t.Run("pi is a term Name/Arity and Arity is an integer less than zero", func(t *testing.T) {
+		var vm VM
+		ok, err := Dynamic(&vm, atomSlash.Apply(NewAtom("foo"), Integer(-1)), Success, nil).Force(context.Background())
+		assert.Equal(t, domainError(validDomainNotLessThanZero, Integer(-1), nil), err)
 		assert.False(t, ok)
 	})
 
-	t.Run("exception in continuation", func(t *testing.T) {
+	t.Run("builtin", func(t *testing.T) {
 		vm := VM{
 			procedures: map[procedureIndicator]procedure{
-				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true, clauses: []clause{
-					{raw: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}},
-				}},
+				{name: NewAtom("foo"), arity: 0}: Predicate0(func(_ *VM, k Cont, env *Env) *Promise {
+					return k(env)
+				}),
 			},
 		}
-
-		ok, err := Retract(&vm, &compound{
-			functor: NewAtom("foo"),
-			args:    []Term{NewVariable()},
-		}, func(_ *Env) *Promise {
-			return Error(errors.New("failed"))
-		}, nil).Force(context.Background())
-		assert.Error(t, err)
+		ok, err := Dynamic(&vm, atomSlash.Apply(NewAtom("foo"), Integer(0)), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, atomSlash.Apply(NewAtom("foo"), Integer(0)), nil), err)
 		assert.False(t, ok)
-
-		// removed
-		assert.Empty(t, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined).clauses)
 	})
 }
 
@@ -3072,6 +3985,70 @@ func TestAbolish(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("list", func(t *testing.T) {
+		foo, bar := procedureIndicator{name: NewAtom("foo"), arity: 1}, procedureIndicator{name: NewAtom("bar"), arity: 2}
+
+		t.Run("ok", func(t *testing.T) {
+			vm := VM{
+				procedures: map[procedureIndicator]procedure{
+					foo: &userDefined{dynamic: true},
+					bar: &userDefined{dynamic: true},
+				},
+			}
+
+			ok, err := Abolish(&vm, List(foo.Term(), bar.Term()), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			assert.Empty(t, vm.procedures)
+		})
+
+		t.Run("empty", func(t *testing.T) {
+			var vm VM
+			ok, err := Abolish(&vm, List(), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("malformed indicator", func(t *testing.T) {
+			vm := VM{
+				procedures: map[procedureIndicator]procedure{
+					foo: &userDefined{dynamic: true},
+				},
+			}
+
+			ok, err := Abolish(&vm, List(foo.Term(), NewAtom("bar")), Success, nil).Force(context.Background())
+			assert.Equal(t, typeError(validTypePredicateIndicator, NewAtom("bar"), nil), err)
+			assert.False(t, ok)
+
+			_, ok = vm.procedures[foo]
+			assert.True(t, ok)
+		})
+
+		t.Run("static procedure", func(t *testing.T) {
+			vm := VM{
+				procedures: map[procedureIndicator]procedure{
+					foo: &userDefined{dynamic: true},
+					bar: &userDefined{},
+				},
+			}
+
+			ok, err := Abolish(&vm, List(foo.Term(), bar.Term()), Success, nil).Force(context.Background())
+			assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, bar.Term(), nil), err)
+			assert.False(t, ok)
+
+			_, ok = vm.procedures[foo]
+			assert.True(t, ok)
+		})
+
+		t.Run("partial list", func(t *testing.T) {
+			var vm VM
+			ok, err := Abolish(&vm, PartialList(NewVariable(), foo.Term()), Success, nil).Force(context.Background())
+			assert.Equal(t, InstantiationError(nil), err)
+			assert.False(t, ok)
+		})
+	})
+
 	t.Run("pi is a variable", func(t *testing.T) {
 		var vm VM
 		ok, err := Abolish(&vm, NewVariable(), Success, nil).Force(context.Background())
@@ -3147,6 +4124,16 @@ func TestAbolish(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("procedure not found", func(t *testing.T) {
+		var vm VM
+		ok, err := Abolish(&vm, &compound{
+			functor: atomSlash,
+			args:    []Term{NewAtom("foo"), Integer(0)},
+		}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
 	t.Run("The predicate indicator pi is that of a static procedure", func(t *testing.T) {
 		vm := VM{
 			procedures: map[procedureIndicator]procedure{
@@ -4055,6 +5042,15 @@ func TestWriteTerm(t *testing.T) {
 			atomEqual.Apply(NewAtom("a"), NewAtom("b")), // ignored
 		))), ok: true, output: `n`},
 
+		{title: `fullstop`, sOrA: w, term: NewAtom("foo"), options: List(atomFullStop.Apply(atomTrue)), ok: true, output: "foo.\n"},
+		{title: `fullstop after graphic`, sOrA: w, term: NewAtom("#"), options: List(atomFullStop.Apply(atomTrue)), ok: true, output: "# .\n"},
+
+		{title: `max_depth`, sOrA: w, term: NewAtom("f").Apply(NewAtom("a"), NewAtom("g").Apply(NewAtom("h").Apply(NewAtom("b")))), options: List(atomMaxDepth.Apply(Integer(2))), ok: true, output: `f(a,g(...))`},
+		{title: `max_depth, unlimited`, sOrA: w, term: NewAtom("f").Apply(NewAtom("g").Apply(NewAtom("h").Apply(NewAtom("b")))), options: List(atomMaxDepth.Apply(Integer(0))), ok: true, output: `f(g(h(b)))`},
+		{title: `max_depth, variable`, sOrA: w, term: NewAtom("foo"), options: List(atomMaxDepth.Apply(x)), err: InstantiationError(nil)},
+		{title: `max_depth, not an integer`, sOrA: w, term: NewAtom("foo"), options: List(atomMaxDepth.Apply(NewAtom("a"))), err: domainError(validDomainWriteOption, atomMaxDepth.Apply(NewAtom("a")), nil)},
+		{title: `max_depth, negative`, sOrA: w, term: NewAtom("foo"), options: List(atomMaxDepth.Apply(Integer(-1))), err: domainError(validDomainWriteOption, atomMaxDepth.Apply(Integer(-1)), nil)},
+
 		{title: `failure`, sOrA: mw, term: NewAtom("foo"), options: List(), err: err},
 	}
 
@@ -4079,6 +5075,29 @@ func TestWriteTerm(t *testing.T) {
 	}
 }
 
+func TestWriteTerm_fullStop(t *testing.T) {
+	vm := VM{operators: operators{}}
+	vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+	vm.operators.define(1000, operatorSpecifierXFY, atomComma)
+	vm.operators.define(700, operatorSpecifierXFX, NewAtom("=="))
+
+	for _, clause := range []Term{
+		atomIf.Apply(NewAtom("foo"), NewAtom("bar")),
+		atomIf.Apply(NewAtom("foo"), atomComma.Apply(NewAtom("bar"), NewAtom("==").Apply(NewAtom("baz"), NewAtom("#")))),
+	} {
+		var buf bytes.Buffer
+		s := &Stream{sink: &buf, mode: ioModeWrite}
+		ok, err := WriteTerm(&vm, s, clause, List(atomQuoted.Apply(atomTrue), atomFullStop.Apply(atomTrue)), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		p := NewParser(&vm, &buf)
+		c, err := p.Term()
+		assert.NoError(t, err)
+		assert.Equal(t, clause, c)
+	}
+}
+
 type mockTerm struct {
 	mock.Mock
 	WriteOptions
@@ -4170,9 +5189,17 @@ func TestCharCode(t *testing.T) {
 	})
 
 	t.Run("code is neither a variable nor a character-code", func(t *testing.T) {
-		ok, err := CharCode(nil, NewVariable(), Integer(-1), Success, nil).Force(context.Background())
-		assert.Equal(t, representationError(flagCharacterCode, nil), err)
-		assert.False(t, ok)
+		t.Run("negative", func(t *testing.T) {
+			ok, err := CharCode(nil, NewVariable(), Integer(-1), Success, nil).Force(context.Background())
+			assert.Equal(t, representationError(flagCharacterCode, nil), err)
+			assert.False(t, ok)
+		})
+
+		t.Run("beyond rune", func(t *testing.T) {
+			ok, err := CharCode(nil, NewVariable(), Integer(1<<32+'a'), Success, nil).Force(context.Background())
+			assert.Equal(t, representationError(flagCharacterCode, nil), err)
+			assert.False(t, ok)
+		})
 	})
 }
 
@@ -4355,6 +5382,68 @@ func TestPutChar(t *testing.T) {
 	}
 }
 
+func TestTab(t *testing.T) {
+	tests := []struct {
+		title         string
+		streamOrAlias func() (Term, func(*testing.T))
+		n             Term
+		ok            bool
+		err           error
+	}{
+		{title: "tab(2+1)", streamOrAlias: func() (Term, func(*testing.T)) {
+			var sb strings.Builder
+			sb.WriteString("a")
+			return NewOutputTextStream(&sb), func(t *testing.T) {
+				assert.Equal(t, "a   ", sb.String())
+			}
+		}, n: atomPlus.Apply(Integer(2), Integer(1)), ok: true},
+		{title: "tab(0)", streamOrAlias: func() (Term, func(*testing.T)) {
+			var sb strings.Builder
+			return NewOutputTextStream(&sb), func(t *testing.T) {
+				assert.Equal(t, "", sb.String())
+			}
+		}, n: Integer(0), ok: true},
+
+		{title: "n is a variable", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewOutputTextStream(nil), nil
+		}, n: NewVariable(), err: InstantiationError(nil)},
+		{title: "n is not an integer", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewOutputTextStream(nil), nil
+		}, n: Float(1.5), err: typeError(validTypeInteger, Float(1.5), nil)},
+		{title: "n is negative", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewOutputTextStream(nil), nil
+		}, n: Integer(-1), err: domainError(validDomainNotLessThanZero, Integer(-1), nil)},
+		{title: "stream is a variable", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewVariable(), nil
+		}, n: Integer(1), err: InstantiationError(nil)},
+		{title: "input stream", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewInputTextStream(nil), nil
+		}, n: Integer(1), err: permissionError(operationOutput, permissionTypeStream, NewInputTextStream(nil), nil)},
+		{title: "binary stream", streamOrAlias: func() (Term, func(*testing.T)) {
+			return NewOutputBinaryStream(nil), nil
+		}, n: Integer(1), err: permissionError(operationOutput, permissionTypeBinaryStream, NewOutputBinaryStream(nil), nil)},
+		{title: "error on write", streamOrAlias: func() (Term, func(*testing.T)) {
+			var m mockWriter
+			m.On("Write", mock.Anything).Return(0, errors.New("failed"))
+			return NewOutputTextStream(&m), nil
+		}, n: Integer(1), err: errors.New("failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			sOrA, test := tt.streamOrAlias()
+			if test != nil {
+				defer test(t)
+			}
+
+			var vm VM
+			ok, err := Tab(&vm, sOrA, tt.n, Success, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestReadTerm(t *testing.T) {
 	t.Run("stream", func(t *testing.T) {
 		f, err := os.Open("testdata/foo.pl")
@@ -4515,6 +5604,42 @@ func TestReadTerm(t *testing.T) {
 		assert.True(t, ok)
 	})
 
+	t.Run("comments", func(t *testing.T) {
+		s := &Stream{source: strings.NewReader("% foo is a fact.\nfoo(/* bar */ a)."), mode: ioModeRead}
+
+		v, comments := NewVariable(), NewVariable()
+
+		var vm VM
+		ok, err := ReadTerm(&vm, s, v, List(&compound{
+			functor: atomComments,
+			args:    []Term{comments},
+		}), func(env *Env) *Promise {
+			assert.Equal(t, &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a")}}, env.Resolve(v))
+			assert.Equal(t, List(
+				atomMinus.Apply(Integer(0), NewAtom("% foo is a fact.")),
+				atomMinus.Apply(Integer(21), NewAtom("/* bar */")),
+			), env.Resolve(comments))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("comment at the end of input", func(t *testing.T) {
+		s := &Stream{source: strings.NewReader("% no new line"), mode: ioModeRead}
+
+		v, comments := NewVariable(), NewVariable()
+
+		var vm VM
+		ok, err := ReadTerm(&vm, s, v, List(atomComments.Apply(comments)), func(env *Env) *Promise {
+			assert.Equal(t, atomEndOfFile, env.Resolve(v))
+			assert.Equal(t, List(atomMinus.Apply(Integer(0), NewAtom("% no new line"))), env.Resolve(comments))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
 	t.Run("multiple reads", func(t *testing.T) {
 		f, err := os.Open("testdata/multi.txt")
 		assert.NoError(t, err)
@@ -4674,32 +5799,150 @@ func TestReadTerm(t *testing.T) {
 
 			s := &Stream{source: f, mode: ioModeRead}
 
-			out := NewVariable()
-			var vm VM
-			ok, err := ReadTerm(&vm, s, out, List(), func(env *Env) *Promise {
-				assert.Equal(t, atomEndOfFile, env.Resolve(out))
+			out := NewVariable()
+			var vm VM
+			ok, err := ReadTerm(&vm, s, out, List(), func(env *Env) *Promise {
+				assert.Equal(t, atomEndOfFile, env.Resolve(out))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+	})
+
+	t.Run("the sequence of tokens cannot be parsed as a term using the current set of operator definitions", func(t *testing.T) {
+		f, err := os.Open("testdata/unexpected_op.txt")
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, f.Close())
+		}()
+
+		s := &Stream{source: f, mode: ioModeRead}
+
+		var vm VM
+		ok, err := ReadTerm(&vm, s, NewVariable(), List(), Success, nil).Force(context.Background())
+		assert.Equal(t, syntaxError(unexpectedTokenError{actual: Token{kind: tokenGraphic, val: "="}}, nil), err)
+		assert.False(t, ok)
+	})
+}
+
+func TestReadTermFromAtom(t *testing.T) {
+	vm := vmWithOperators(operator{priority: 500, specifier: operatorSpecifierYFX, name: atomPlus})
+
+	t.Run("ok", func(t *testing.T) {
+		for _, a := range []string{"f(X, Y, X) + Z", "f(X, Y, X) + Z.", "f(X, Y, X) + Z % comment"} {
+			t.Run(a, func(t *testing.T) {
+				v, singletons, variableNames := NewVariable(), NewVariable(), NewVariable()
+				ok, err := ReadTermFromAtom(vm, NewAtom(a), v, List(atomSingletons.Apply(singletons), atomVariableNames.Apply(variableNames)), func(env *Env) *Promise {
+					c, ok := env.Resolve(v).(Compound)
+					assert.True(t, ok)
+					assert.Equal(t, atomPlus, c.Functor())
+					f := env.Resolve(c.Arg(0)).(Compound)
+					x, y, z := f.Arg(0), f.Arg(1), c.Arg(1)
+					assert.Equal(t, x, f.Arg(2))
+					assert.Equal(t, List(y, z), env.Resolve(singletons))
+					assert.Equal(t, List(atomEqual.Apply(NewAtom("X"), x), atomEqual.Apply(NewAtom("Y"), y), atomEqual.Apply(NewAtom("Z"), z)), env.Resolve(variableNames))
+					return Bool(true)
+				}, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			})
+		}
+	})
+
+	t.Run("double_quotes", func(t *testing.T) {
+		vm := VM{doubleQuotes: doubleQuotesAtom}
+		v := NewVariable()
+		ok, err := ReadTermFromAtom(&vm, NewAtom(`"abc"`), v, List(), func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("abc"), env.Resolve(v))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		vm := vmWithOperators(operator{priority: 700, specifier: operatorSpecifierXFX, name: atomEqual})
+
+		t.Run("shared", func(t *testing.T) {
+			v, variables := NewVariable(), NewVariable()
+			ok, err := ReadTermFromAtom(vm, NewAtom("@(g(A, A), [A = f(B)])"), v, List(atomCycles.Apply(atomTrue), atomVariables.Apply(variables)), func(env *Env) *Promise {
+				g, ok := env.Resolve(v).(Compound)
+				assert.True(t, ok)
+				assert.Equal(t, NewAtom("g"), g.Functor())
+				f, ok := env.Resolve(g.Arg(0)).(Compound)
+				assert.True(t, ok)
+				assert.Equal(t, NewAtom("f"), f.Functor())
+				assert.Equal(t, f, env.Resolve(g.Arg(1)))
+				assert.Equal(t, List(f.Arg(0)), env.Resolve(variables))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("cyclic", func(t *testing.T) {
+			v := NewVariable()
+			ok, err := ReadTermFromAtom(vm, NewAtom("@(X, [X = f(X)])"), v, List(atomCycles.Apply(atomTrue)), func(env *Env) *Promise {
+				f, ok := env.Resolve(v).(Compound)
+				assert.True(t, ok)
+				assert.Equal(t, NewAtom("f"), f.Functor())
+				assert.Equal(t, f, env.Resolve(f.Arg(0)))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		})
+
+		t.Run("false", func(t *testing.T) {
+			v := NewVariable()
+			ok, err := ReadTermFromAtom(vm, NewAtom("@(X, [X = a])"), v, List(atomCycles.Apply(atomFalse)), func(env *Env) *Promise {
+				c, ok := env.Resolve(v).(Compound)
+				assert.True(t, ok)
+				assert.Equal(t, atomAtSign, c.Functor())
 				return Bool(true)
 			}, nil).Force(context.Background())
 			assert.NoError(t, err)
 			assert.True(t, ok)
 		})
 
+		t.Run("invalid value", func(t *testing.T) {
+			ok, err := ReadTermFromAtom(vm, NewAtom("foo"), NewVariable(), List(atomCycles.Apply(NewAtom("yes"))), Success, nil).Force(context.Background())
+			assert.Equal(t, domainError(validDomainReadOption, atomCycles.Apply(NewAtom("yes")), nil), err)
+			assert.False(t, ok)
+		})
 	})
 
-	t.Run("the sequence of tokens cannot be parsed as a term using the current set of operator definitions", func(t *testing.T) {
-		f, err := os.Open("testdata/unexpected_op.txt")
-		assert.NoError(t, err)
-		defer func() {
-			assert.NoError(t, f.Close())
-		}()
+	t.Run("atom is a variable", func(t *testing.T) {
+		ok, err := ReadTermFromAtom(vm, NewVariable(), NewVariable(), List(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
 
-		s := &Stream{source: f, mode: ioModeRead}
+	t.Run("atom is neither a variable nor an atom", func(t *testing.T) {
+		ok, err := ReadTermFromAtom(vm, Integer(0), NewVariable(), List(), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeAtom, Integer(0), nil), err)
+		assert.False(t, ok)
+	})
 
-		var vm VM
-		ok, err := ReadTerm(&vm, s, NewVariable(), List(), Success, nil).Force(context.Background())
-		assert.Equal(t, syntaxError(unexpectedTokenError{actual: Token{kind: tokenGraphic, val: "="}}, nil), err)
+	t.Run("invalid option", func(t *testing.T) {
+		ok, err := ReadTermFromAtom(vm, NewAtom("foo"), NewVariable(), List(NewAtom("bar")), Success, nil).Force(context.Background())
+		assert.Equal(t, domainError(validDomainReadOption, NewAtom("bar"), nil), err)
 		assert.False(t, ok)
 	})
+
+	t.Run("trailing tokens", func(t *testing.T) {
+		for _, a := range []string{"foo. bar", "foo. bar.", "foo bar"} {
+			t.Run(a, func(t *testing.T) {
+				ok, err := ReadTermFromAtom(vm, NewAtom(a), NewVariable(), List(), Success, nil).Force(context.Background())
+				assert.False(t, ok)
+				e, ok := err.(Exception)
+				assert.True(t, ok)
+				assert.Equal(t, atomSyntaxError, e.Term().(Compound).Arg(0).(Compound).Functor())
+			})
+		}
+	})
 }
 
 func TestGetByte(t *testing.T) {
@@ -5359,20 +6602,35 @@ func TestPeekChar(t *testing.T) {
 
 func Test_Halt(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
-		var exitCalled bool
-		osExit = func(code int) {
-			assert.Equal(t, 2, code)
-			exitCalled = true
+		var haltCalled bool
+		vm := VM{
+			OnHalt: func(code int) {
+				assert.Equal(t, 2, code)
+				haltCalled = true
+			},
 		}
-		defer func() {
-			osExit = os.Exit
-		}()
 
-		ok, err := Halt(nil, Integer(2), Success, nil).Force(context.Background())
-		assert.NoError(t, err)
+		ok, err := Halt(&vm, Integer(2), Success, nil).Force(context.Background())
+		assert.Equal(t, haltError{code: 2}, err)
+		assert.False(t, ok)
+
+		assert.True(t, haltCalled)
+
+		code, ok := ExitCode(err)
 		assert.True(t, ok)
+		assert.Equal(t, 2, code)
+	})
+
+	t.Run("not caught", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("halt"), arity: 1}: Predicate1(Halt),
+			},
+		}
 
-		assert.True(t, exitCalled)
+		ok, err := Catch(&vm, NewAtom("halt").Apply(Integer(1)), NewVariable(), atomTrue, Success, nil).Force(context.Background())
+		assert.Equal(t, haltError{code: 1}, err)
+		assert.False(t, ok)
 	})
 
 	t.Run("n is a variable", func(t *testing.T) {
@@ -5390,6 +6648,85 @@ func Test_Halt(t *testing.T) {
 	})
 }
 
+func TestPrintMessage(t *testing.T) {
+	tests := []struct {
+		title   string
+		kind    Term
+		message Term
+		output  string
+	}{
+		{title: "error", kind: atomError, message: NewAtom("foo"), output: "ERROR: foo\n"},
+		{title: "warning", kind: atomWarning, message: NewAtom("foo"), output: "Warning: foo\n"},
+		{title: "informational", kind: atomInformational, message: NewAtom("foo"), output: "% foo\n"},
+		{title: "quoted", kind: atomWarning, message: NewAtom("f").Apply(NewAtom("Foo")), output: "Warning: f('Foo')\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			var buf bytes.Buffer
+			var vm VM
+			vm.SetUserError(&buf)
+			ok, err := PrintMessage(&vm, tt.kind, tt.message, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.output, buf.String())
+		})
+	}
+
+	t.Run("message hook", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		t.Run("succeeds", func(t *testing.T) {
+			buf.Reset()
+			vm := VM{
+				procedures: map[procedureIndicator]procedure{
+					{name: atomMessageHook, arity: 3}: Predicate3(func(_ *VM, message, kind, lines Term, k Cont, env *Env) *Promise {
+						assert.Equal(t, NewAtom("foo"), env.Resolve(message))
+						assert.Equal(t, atomWarning, env.Resolve(kind))
+						assert.Equal(t, List(NewAtom("Warning: foo")), env.simplify(lines))
+						return k(env)
+					}),
+				},
+			}
+			vm.SetUserError(&buf)
+			ok, err := PrintMessage(&vm, atomWarning, NewAtom("foo"), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Empty(t, buf.String())
+		})
+
+		t.Run("fails", func(t *testing.T) {
+			buf.Reset()
+			vm := VM{
+				procedures: map[procedureIndicator]procedure{
+					{name: atomMessageHook, arity: 3}: Predicate3(func(_ *VM, _, _, _ Term, _ Cont, _ *Env) *Promise {
+						return Bool(false)
+					}),
+				},
+			}
+			vm.SetUserError(&buf)
+			ok, err := PrintMessage(&vm, atomWarning, NewAtom("foo"), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "Warning: foo\n", buf.String())
+		})
+	})
+
+	t.Run("kind is a variable", func(t *testing.T) {
+		var vm VM
+		ok, err := PrintMessage(&vm, NewVariable(), NewAtom("foo"), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("kind is neither a variable nor an atom", func(t *testing.T) {
+		var vm VM
+		ok, err := PrintMessage(&vm, Integer(0), NewAtom("foo"), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeAtom, Integer(0), nil), err)
+		assert.False(t, ok)
+	})
+}
+
 func TestClause(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		x := NewVariable()
@@ -5433,6 +6770,67 @@ func TestClause(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("alternatives", func(t *testing.T) {
+		rule := atomIf.Apply(NewAtom("foo"), atomSemiColon.Apply(NewAtom("a"), NewAtom("b")))
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 0}: &userDefined{public: true, clauses: []clause{
+					{raw: rule},
+					{raw: rule, alternative: true},
+				}},
+			},
+		}
+
+		body := NewVariable()
+		var c int
+		ok, err := Clause(&vm, NewAtom("foo"), body, func(env *Env) *Promise {
+			assert.Equal(t, atomSemiColon.Apply(NewAtom("a"), NewAtom("b")), env.Resolve(body))
+			c++
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 1, c)
+	})
+
+	t.Run("duplicate facts", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 0}: &userDefined{public: true, clauses: []clause{
+					{raw: NewAtom("foo")},
+					{raw: NewAtom("foo")},
+				}},
+			},
+		}
+
+		var c int
+		ok, err := Clause(&vm, NewAtom("foo"), atomTrue, func(env *Env) *Promise {
+			c++
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 2, c)
+	})
+
+	t.Run("copied", func(t *testing.T) {
+		x := NewVariable()
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 1}: &userDefined{public: true, clauses: []clause{
+					{raw: NewAtom("foo").Apply(x)},
+				}},
+			},
+		}
+
+		ok, err := Clause(&vm, NewAtom("foo").Apply(NewAtom("a")), atomTrue, func(env *Env) *Promise {
+			assert.Equal(t, x, env.Resolve(x))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		var vm VM
 		ok, err := Clause(&vm, NewAtom("foo"), atomTrue, Success, nil).Force(context.Background())
@@ -5526,6 +6924,19 @@ func TestAtomLength(t *testing.T) {
 
 		// 8.16.1.3 Errors
 		{title: "d", atom: NewAtom("atom"), length: Integer(-1), err: domainError(validDomainNotLessThanZero, Integer(-1), nil)},
+
+		// Code points, not bytes
+		{title: "atom_length('héllo', N).", atom: NewAtom("héllo"), length: n, ok: true, env: map[Variable]Term{
+			n: Integer(5),
+		}},
+		{title: "atom_length('😀', N).", atom: NewAtom("😀"), length: n, ok: true, env: map[Variable]Term{
+			n: Integer(1),
+		}},
+		{title: "atom_length('a😀b👍', 4).", atom: NewAtom("a😀b👍"), length: Integer(4), ok: true},
+		{title: "atom_length('👨‍👩‍👧', N).", atom: NewAtom("👨\u200d👩\u200d👧"), length: n, ok: true, env: map[Variable]Term{
+			n: Integer(5),
+		}},
+		{title: "atom_length(f(a), N).", atom: NewAtom("f").Apply(NewAtom("a")), length: n, err: typeError(validTypeAtom, NewAtom("f").Apply(NewAtom("a")), nil)},
 	}
 
 	for _, tt := range tests {
@@ -5666,6 +7077,47 @@ func TestSubAtom(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("multiple solutions with unicode", func(t *testing.T) {
+		before, after := NewVariable(), NewVariable()
+		var c int
+		ok, err := SubAtom(nil, NewAtom("日本語と日本"), before, Integer(2), after, NewAtom("日本"), func(env *Env) *Promise {
+			switch c {
+			case 0:
+				assert.Equal(t, Integer(0), env.Resolve(before))
+				assert.Equal(t, Integer(4), env.Resolve(after))
+			case 1:
+				assert.Equal(t, Integer(4), env.Resolve(before))
+				assert.Equal(t, Integer(0), env.Resolve(after))
+			default:
+				assert.Fail(t, "unreachable")
+			}
+			c++
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 2, c)
+	})
+
+	t.Run("enumerate all sub atoms", func(t *testing.T) {
+		before, length, after, sub := NewVariable(), NewVariable(), NewVariable(), NewVariable()
+		var subs []Term
+		ok, err := SubAtom(nil, NewAtom("ab"), before, length, after, sub, func(env *Env) *Promise {
+			subs = append(subs, tuple(env.Resolve(before), env.Resolve(length), env.Resolve(after), env.Resolve(sub)))
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, []Term{
+			tuple(Integer(0), Integer(0), Integer(2), NewAtom("")),
+			tuple(Integer(0), Integer(1), Integer(1), NewAtom("a")),
+			tuple(Integer(0), Integer(2), Integer(0), NewAtom("ab")),
+			tuple(Integer(1), Integer(0), Integer(1), NewAtom("")),
+			tuple(Integer(1), Integer(1), Integer(0), NewAtom("b")),
+			tuple(Integer(2), Integer(0), Integer(0), NewAtom("")),
+		}, subs)
+	})
+
 	t.Run("get the first char", func(t *testing.T) {
 		char := NewVariable()
 		ok, err := SubAtom(nil, NewAtom("a"), Integer(0), Integer(1), Integer(0), char, func(env *Env) *Promise {
@@ -5731,6 +7183,73 @@ func TestSubAtom(t *testing.T) {
 	})
 }
 
+func TestSplitString(t *testing.T) {
+	l := NewVariable()
+
+	tests := []struct {
+		title                   string
+		str, sepChars, padChars Term
+		subStrings              Term
+		ok                      bool
+		err                     error
+		env                     map[Variable]Term
+	}{
+		{title: `split_string('a.b.c.d', '.', '', L).`, str: NewAtom("a.b.c.d"), sepChars: NewAtom("."), padChars: NewAtom(""), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom("b"), NewAtom("c"), NewAtom("d")),
+		}},
+		{title: `split_string('/home//jan///nice/path', '/', '', L).`, str: NewAtom("/home//jan///nice/path"), sepChars: NewAtom("/"), padChars: NewAtom(""), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom(""), NewAtom("home"), NewAtom(""), NewAtom("jan"), NewAtom(""), NewAtom(""), NewAtom("nice"), NewAtom("path")),
+		}},
+		{title: `split_string('SWI-Prolog, 7.0', ',', ' ', L).`, str: NewAtom("SWI-Prolog, 7.0"), sepChars: NewAtom(","), padChars: NewAtom(" "), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("SWI-Prolog"), NewAtom("7.0")),
+		}},
+		{title: `split_string('  a word ', '', ' ', L).`, str: NewAtom("  a word "), sepChars: NewAtom(""), padChars: NewAtom(" "), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a word")),
+		}},
+		{title: `split_string('', '', '', L).`, str: NewAtom(""), sepChars: NewAtom(""), padChars: NewAtom(""), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("")),
+		}},
+		{title: `split_string('a  b', ' ', ' ', L).`, str: NewAtom("a  b"), sepChars: NewAtom(" "), padChars: NewAtom(" "), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom(""), NewAtom("b")),
+		}},
+		{title: `split_string('あ、い、う', '、', '', L).`, str: NewAtom("あ、い、う"), sepChars: NewAtom("、"), padChars: NewAtom(""), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("あ"), NewAtom("い"), NewAtom("う")),
+		}},
+		{title: `split_string("a.b", ".", "", L). (chars)`, str: CharList("a.b"), sepChars: CharList("."), padChars: atomEmptyList, subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom("b")),
+		}},
+		{title: `split_string("a.b", ".", "", L). (codes)`, str: CodeList("a.b"), sepChars: CodeList("."), padChars: atomEmptyList, subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom("b")),
+		}},
+		{title: `split_string([a, ' ', b], [' '], [], L).`, str: List(NewAtom("a"), NewAtom(" "), NewAtom("b")), sepChars: List(NewAtom(" ")), padChars: atomEmptyList, subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom("b")),
+		}},
+		{title: `split_string([0'a, 0',, 0'b], ',', '', L).`, str: List(Integer('a'), Integer(','), Integer('b')), sepChars: NewAtom(","), padChars: NewAtom(""), subStrings: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("a"), NewAtom("b")),
+		}},
+		{title: `split_string('a,b', ',', '', [a]).`, str: NewAtom("a,b"), sepChars: NewAtom(","), padChars: NewAtom(""), subStrings: List(NewAtom("a")), ok: false},
+		{title: "str is a variable", str: NewVariable(), sepChars: NewAtom(""), padChars: NewAtom(""), subStrings: l, err: InstantiationError(nil)},
+		{title: "sepChars is not an atom", str: NewAtom("a"), sepChars: Integer(0), padChars: NewAtom(""), subStrings: l, err: typeError(validTypeAtom, Integer(0), nil)},
+		{title: "padChars is a variable", str: NewAtom("a"), sepChars: NewAtom(""), padChars: NewVariable(), subStrings: l, err: InstantiationError(nil)},
+		{title: "str has a variable element", str: List(NewAtom("a"), NewVariable()), sepChars: NewAtom(""), padChars: NewAtom(""), subStrings: l, err: InstantiationError(nil)},
+		{title: "str has a non-character element", str: List(NewAtom("a"), NewAtom("bc")), sepChars: NewAtom(""), padChars: NewAtom(""), subStrings: l, err: typeError(validTypeCharacter, NewAtom("bc"), nil)},
+		{title: "str is a partial list", str: PartialList(NewVariable(), NewAtom("a")), sepChars: NewAtom(""), padChars: NewAtom(""), subStrings: l, err: InstantiationError(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := SplitString(nil, tt.str, tt.sepChars, tt.padChars, tt.subStrings, func(env *Env) *Promise {
+				for k, v := range tt.env {
+					assert.Equal(t, v, env.Resolve(k))
+				}
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestAtomChars(t *testing.T) {
 	l := NewVariable()
 	str := NewVariable()
@@ -5767,7 +7286,7 @@ func TestAtomChars(t *testing.T) {
 
 		// 8.16.4.3 Errors
 		{title: "a", atom: x, list: PartialList(y, NewAtom("a")), err: InstantiationError(nil)},
-		{title: "b", atom: Integer(0), list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), err: typeError(validTypeAtom, Integer(0), nil)},
+		{title: "b", atom: NewAtom("f").Apply(Integer(0)), list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), err: typeError(validTypeAtom, NewAtom("f").Apply(Integer(0)), nil)},
 		{title: "c: atom is a variable", atom: x, list: Integer(0), err: typeError(validTypeList, Integer(0), nil)},
 		{title: "c: atom is an atom", atom: NewAtom("a"), list: Integer(0), err: typeError(validTypeList, Integer(0), nil)},
 		{title: "d", atom: x, list: List(y, NewAtom("a")), err: InstantiationError(nil)},
@@ -5779,6 +7298,13 @@ func TestAtomChars(t *testing.T) {
 		{title: "atom_chars('ant', ['a', X, 't']).", atom: NewAtom("ant"), list: List(NewAtom("a"), x, NewAtom("t")), ok: true, env: map[Variable]Term{
 			x: NewAtom("n"),
 		}},
+		{title: "atom_chars(-12, L).", atom: Integer(-12), list: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("-"), NewAtom("1"), NewAtom("2")),
+		}},
+		{title: "atom_chars(1.5, L).", atom: Float(1.5), list: l, ok: true, env: map[Variable]Term{
+			l: List(NewAtom("1"), NewAtom("."), NewAtom("5")),
+		}},
+		{title: "atom_chars(0, [a, b, c]).", atom: Integer(0), list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ok: false},
 	}
 
 	for _, tt := range tests {
@@ -5832,7 +7358,7 @@ func TestAtomCodes(t *testing.T) {
 
 		// 8.16.5.3 Errors
 		{title: "a", atom: x, list: PartialList(y, Integer(0)), err: InstantiationError(nil)},
-		{title: "b", atom: Integer(0), list: l, err: typeError(validTypeAtom, Integer(0), nil)},
+		{title: "b", atom: NewAtom("f").Apply(Integer(0)), list: l, err: typeError(validTypeAtom, NewAtom("f").Apply(Integer(0)), nil)},
 		{title: "c: atom is a variable", atom: x, list: Integer(0), err: typeError(validTypeList, Integer(0), nil)},
 		{title: "c: atom is an atom", atom: NewAtom("abc"), list: Integer(0), err: typeError(validTypeList, Integer(0), nil)},
 		{title: "d", atom: x, list: List(y, Integer('b'), Integer('c')), err: InstantiationError(nil)},
@@ -5844,6 +7370,9 @@ func TestAtomCodes(t *testing.T) {
 		{title: "atom_codes('ant', [0'a, X, 0't]).", atom: NewAtom("ant"), list: List(Integer('a'), x, Integer('t')), ok: true, env: map[Variable]Term{
 			x: Integer('n'),
 		}},
+		{title: "atom_codes(42, L).", atom: Integer(42), list: l, ok: true, env: map[Variable]Term{
+			l: List(Integer('4'), Integer('2')),
+		}},
 	}
 
 	for _, tt := range tests {
@@ -5897,6 +7426,23 @@ func TestNumberChars(t *testing.T) {
 		assert.True(t, ok)
 	})
 
+	t.Run("chars to number with exponent", func(t *testing.T) {
+		num := NewVariable()
+
+		ok, err := NumberChars(nil, num, CharList("2.3e5"), func(env *Env) *Promise {
+			assert.Equal(t, Float(2.3e5), env.Resolve(num))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("chars is not a number", func(t *testing.T) {
+		_, err := NumberChars(nil, NewVariable(), CharList("2.3x"), Success, nil).Force(context.Background())
+		_, ok := NewEnv().Unify(atomError.Apply(atomSyntaxError.Apply(NewVariable()), NewVariable()), err.(Exception).term)
+		assert.True(t, ok)
+	})
+
 	t.Run("both provided", func(t *testing.T) {
 		t.Run("3.3", func(t *testing.T) {
 			ok, err := NumberChars(nil, Float(3.3), List(NewAtom("3"), atomDot, NewAtom("3")), Success, nil).Force(context.Background())
@@ -6037,6 +7583,15 @@ func TestNumberCodes(t *testing.T) {
 		{title: "number_codes(A, [0'4, 0'2, 0'., 0'0, 0'e, 0'-, 0'1]).", number: a, list: List(Integer('4'), Integer('2'), Integer('.'), Integer('0'), Integer('e'), Integer('-'), Integer('1')), ok: true, env: map[Variable]Term{
 			a: Float(4.2),
 		}},
+		{title: "number_codes(A, \"0x1F\").", number: a, list: CodeList("0x1F"), ok: true, env: map[Variable]Term{
+			a: Integer(31),
+		}},
+		{title: "number_codes(A, \"2.3e5\").", number: a, list: CodeList("2.3e5"), ok: true, env: map[Variable]Term{
+			a: Float(2.3e5),
+		}},
+		{title: "number_codes(A, \"0'a\").", number: a, list: CodeList("0'a"), ok: true, env: map[Variable]Term{
+			a: Integer('a'),
+		}},
 
 		// 8.16.8.3 Errors
 		{title: "a", number: a, list: l, err: InstantiationError(nil)},
@@ -6072,6 +7627,111 @@ func TestNumberCodes(t *testing.T) {
 	}
 }
 
+func TestAtomNumber(t *testing.T) {
+	tests := []struct {
+		title        string
+		atom, number Term
+		ok           bool
+		err          error
+		result       Term
+	}{
+		{title: "integer", atom: NewAtom("12"), number: NewVariable(), ok: true, result: Integer(12)},
+		{title: "negative integer", atom: NewAtom("-12"), number: NewVariable(), ok: true, result: Integer(-12)},
+		{title: "float", atom: NewAtom("1.5"), number: NewVariable(), ok: true, result: Float(1.5)},
+		{title: "hexadecimal", atom: NewAtom("0x1f"), number: NewVariable(), ok: true, result: Integer(31)},
+		{title: "matching number", atom: NewAtom("12"), number: Integer(12), ok: true},
+		{title: "different number", atom: NewAtom("12"), number: Integer(13), ok: false},
+		{title: "not a number", atom: NewAtom("foo"), number: NewVariable(), ok: false},
+		{title: "trailing junk", atom: NewAtom("12foo"), number: NewVariable(), ok: false},
+		{title: "leading whitespace", atom: NewAtom(" 12"), number: NewVariable(), ok: false},
+		{title: "trailing whitespace", atom: NewAtom("12 "), number: NewVariable(), ok: false},
+		{title: "empty", atom: NewAtom(""), number: NewVariable(), ok: false},
+		{title: "number to atom", atom: NewVariable(), number: Float(-1.5), ok: true, result: NewAtom("-1.5")},
+		{title: "both are variables", atom: NewVariable(), number: NewVariable(), err: InstantiationError(nil)},
+		{title: "number is neither a variable nor a number", atom: NewVariable(), number: NewAtom("foo"), err: typeError(validTypeNumber, NewAtom("foo"), nil)},
+		{title: "atom is neither a variable nor an atom", atom: Integer(12), number: NewVariable(), err: typeError(validTypeAtom, Integer(12), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := AtomNumber(nil, tt.atom, tt.number, func(env *Env) *Promise {
+				if tt.result != nil {
+					if _, ok := tt.atom.(Variable); ok {
+						assert.Equal(t, tt.result, env.Resolve(tt.atom))
+					} else {
+						assert.Equal(t, tt.result, env.Resolve(tt.number))
+					}
+				}
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestTermToAtom(t *testing.T) {
+	vm := vmWithOperators(operator{priority: 500, specifier: operatorSpecifierYFX, name: atomPlus})
+
+	t.Run("term to atom", func(t *testing.T) {
+		atom := NewVariable()
+		ok, err := TermToAtom(vm, NewAtom("f").Apply(NewAtom("a b"), atomPlus.Apply(Integer(1), Integer(2))), atom, func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("f('a b',1+2)"), env.Resolve(atom))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("atom to term", func(t *testing.T) {
+		term := NewVariable()
+		ok, err := TermToAtom(vm, term, NewAtom("f(a, 1+2) % comment"), func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("f").Apply(NewAtom("a"), atomPlus.Apply(Integer(1), Integer(2))), env.Resolve(term))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		atom, term := NewVariable(), NewVariable()
+		ok, err := TermToAtom(vm, NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), atom, func(env *Env) *Promise {
+			return TermToAtom(vm, term, atom, func(env *Env) *Promise {
+				assert.Equal(t, NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), env.Resolve(term))
+				return Bool(true)
+			}, env)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("both are variables", func(t *testing.T) {
+		ok, err := TermToAtom(vm, NewVariable(), NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("atom is neither a variable nor an atom", func(t *testing.T) {
+		ok, err := TermToAtom(vm, NewVariable(), Integer(0), Success, nil).Force(context.Background())
+		assert.Equal(t, typeError(validTypeAtom, Integer(0), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		ok, err := TermToAtom(vm, NewVariable(), NewAtom("f(a"), Success, nil).Force(context.Background())
+		assert.False(t, ok)
+		e, ok := err.(Exception)
+		assert.True(t, ok)
+		assert.Equal(t, atomSyntaxError, e.Term().(Compound).Arg(0).(Compound).Functor())
+	})
+
+	t.Run("trailing tokens", func(t *testing.T) {
+		ok, err := TermToAtom(vm, NewVariable(), NewAtom("a b"), Success, nil).Force(context.Background())
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
 func TestStreamProperty(t *testing.T) {
 	f, err := os.Open("testdata/empty.txt")
 	assert.NoError(t, err)
@@ -6081,7 +7741,7 @@ func TestStreamProperty(t *testing.T) {
 
 	ss := []*Stream{
 		{source: f, mode: ioModeRead, alias: NewAtom("a"), reposition: true},
-		{sink: f, mode: ioModeWrite, alias: NewAtom("b"), reposition: false},
+		{sink: f, mode: ioModeWrite, alias: NewAtom("b"), reposition: false, streamType: streamTypeBinary},
 		{sink: f, mode: ioModeAppend, alias: NewAtom("c"), reposition: true},
 	}
 
@@ -6147,12 +7807,23 @@ func TestStreamProperty(t *testing.T) {
 			},
 		},
 
+		{
+			title:    "type",
+			stream:   s,
+			property: atomType.Apply(atomBinary),
+			ok:       true,
+			env: []map[Variable]Term{
+				{s: ss[1]},
+			},
+		},
+
 		// 8.11.8.3 Errors
 		{title: "b", stream: Integer(0), property: p, err: domainError(validDomainStream, Integer(0), nil)},
 		{title: "c: unknown atom", stream: s, property: NewAtom("foo"), err: domainError(validDomainStreamProperty, NewAtom("foo"), nil)},
 		{title: "c: compound with multiple args", stream: s, property: NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), err: domainError(validDomainStreamProperty, NewAtom("f").Apply(NewAtom("a"), NewAtom("b")), nil)},
 		{title: "c: compound with an unexpected integer arg", stream: s, property: atomAlias.Apply(Integer(0)), err: domainError(validDomainStreamProperty, atomAlias.Apply(Integer(0)), nil)},
 		{title: "c: compound with an unexpected atom arg", stream: s, property: atomPosition.Apply(NewAtom("foo")), err: domainError(validDomainStreamProperty, atomPosition.Apply(NewAtom("foo")), nil)},
+		{title: "c: type with an unexpected integer arg", stream: s, property: atomType.Apply(Integer(0)), err: domainError(validDomainStreamProperty, atomType.Apply(Integer(0)), nil)},
 		{title: "c: unknown compound", stream: s, property: NewAtom("foo").Apply(NewAtom("bar")), err: domainError(validDomainStreamProperty, NewAtom("foo").Apply(NewAtom("bar")), nil)},
 		{title: "c: unexpected arg", stream: s, property: Integer(0), err: domainError(validDomainStreamProperty, Integer(0), nil)},
 	}
@@ -6461,6 +8132,50 @@ func TestSetPrologFlag(t *testing.T) {
 		})
 	})
 
+	t.Run("digit_separators", func(t *testing.T) {
+		t.Run("on", func(t *testing.T) {
+			var vm VM
+			ok, err := SetPrologFlag(&vm, atomDigitSeparators, atomOn, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.True(t, vm.digitSeparators)
+		})
+
+		t.Run("off", func(t *testing.T) {
+			vm := VM{digitSeparators: true}
+			ok, err := SetPrologFlag(&vm, atomDigitSeparators, atomOff, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, vm.digitSeparators)
+		})
+
+		t.Run("unknown", func(t *testing.T) {
+			var vm VM
+			ok, err := SetPrologFlag(&vm, atomDigitSeparators, NewAtom("foo"), Success, nil).Force(context.Background())
+			assert.Error(t, err)
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("occurs_check", func(t *testing.T) {
+		for _, o := range []occursCheck{occursCheckTrue, occursCheckError, occursCheckFalse} {
+			t.Run(o.String(), func(t *testing.T) {
+				vm := VM{occursCheck: occursCheckTrue}
+				ok, err := SetPrologFlag(&vm, atomOccursCheck, NewAtom(o.String()), Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+				assert.Equal(t, o, vm.occursCheck)
+			})
+		}
+
+		t.Run("unknown", func(t *testing.T) {
+			var vm VM
+			ok, err := SetPrologFlag(&vm, atomOccursCheck, NewAtom("foo"), Success, nil).Force(context.Background())
+			assert.Error(t, err)
+			assert.False(t, ok)
+		})
+	})
+
 	t.Run("debug", func(t *testing.T) {
 		t.Run("on", func(t *testing.T) {
 			var vm VM
@@ -6674,6 +8389,12 @@ func TestCurrentPrologFlag(t *testing.T) {
 			case 8:
 				assert.Equal(t, atomDoubleQuotes, env.Resolve(flag))
 				assert.Equal(t, NewAtom(vm.doubleQuotes.String()), env.Resolve(value))
+			case 9:
+				assert.Equal(t, atomDigitSeparators, env.Resolve(flag))
+				assert.Equal(t, atomOff, env.Resolve(value))
+			case 10:
+				assert.Equal(t, atomOccursCheck, env.Resolve(flag))
+				assert.Equal(t, atomFalse, env.Resolve(value))
 			default:
 				assert.Fail(t, "unreachable")
 			}
@@ -6682,7 +8403,7 @@ func TestCurrentPrologFlag(t *testing.T) {
 		}, nil).Force(context.Background())
 		assert.NoError(t, err)
 		assert.False(t, ok)
-		assert.Equal(t, 9, c)
+		assert.Equal(t, 11, c)
 	})
 
 	t.Run("flag is neither a variable nor an atom", func(t *testing.T) {
@@ -7028,9 +8749,24 @@ func TestNth0(t *testing.T) {
 			}, results)
 		})
 
-		t.Run("list is an improper list", func(t *testing.T) {
-			_, err := Nth0(nil, NewVariable(), PartialList(NewVariable(), NewAtom("a")), NewVariable(), Failure, nil).Force(context.Background())
-			assert.Equal(t, InstantiationError(nil), err)
+		t.Run("list is a partial list", func(t *testing.T) {
+			pair := atomMinus
+			var (
+				n       = NewVariable()
+				elem    = NewVariable()
+				results []Term
+			)
+			ok, err := Nth0(nil, n, PartialList(NewVariable(), NewAtom("a"), NewAtom("b")), elem, func(env *Env) *Promise {
+				results = append(results, pair.Apply(env.Resolve(n), env.Resolve(elem)))
+				return Bool(false)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.Equal(t, []Term{
+				pair.Apply(Integer(0), NewAtom("a")),
+				pair.Apply(Integer(1), NewAtom("b")),
+			}, results)
 		})
 	})
 
@@ -7055,9 +8791,30 @@ func TestNth0(t *testing.T) {
 			})
 		})
 
-		t.Run("list is an improper list", func(t *testing.T) {
-			_, err := Nth0(nil, Integer(1), PartialList(NewVariable(), NewAtom("a")), NewVariable(), Success, nil).Force(context.Background())
-			assert.Equal(t, InstantiationError(nil), err)
+		t.Run("list is a partial list", func(t *testing.T) {
+			t.Run("n is within the instantiated prefix", func(t *testing.T) {
+				ok, err := Nth0(nil, Integer(0), PartialList(NewVariable(), NewAtom("a")), NewAtom("a"), Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			})
+
+			t.Run("n is beyond the instantiated prefix", func(t *testing.T) {
+				l, elem := NewVariable(), NewVariable()
+				ok, err := Nth0(nil, Integer(2), l, elem, func(env *Env) *Promise {
+					iter := ListIterator{List: l, Env: env, AllowPartial: true}
+					assert.True(t, iter.Next())
+					assert.True(t, iter.Next())
+					assert.True(t, iter.Next())
+					assert.Equal(t, elem, env.Resolve(iter.Current()))
+					assert.False(t, iter.Next())
+					assert.NoError(t, iter.Err())
+					_, ok := env.Resolve(iter.Suffix()).(Variable)
+					assert.True(t, ok)
+					return Bool(true)
+				}, NewEnv().bind(l, PartialList(NewVariable(), NewAtom("a")))).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			})
 		})
 	})
 
@@ -7090,9 +8847,24 @@ func TestNth1(t *testing.T) {
 			}, results)
 		})
 
-		t.Run("list is an improper list", func(t *testing.T) {
-			_, err := Nth1(nil, NewVariable(), PartialList(NewVariable(), NewAtom("a")), NewVariable(), Failure, nil).Force(context.Background())
-			assert.Equal(t, InstantiationError(nil), err)
+		t.Run("list is a partial list", func(t *testing.T) {
+			pair := atomMinus
+			var (
+				n       = NewVariable()
+				elem    = NewVariable()
+				results []Term
+			)
+			ok, err := Nth1(nil, n, PartialList(NewVariable(), NewAtom("a"), NewAtom("b")), elem, func(env *Env) *Promise {
+				results = append(results, pair.Apply(env.Resolve(n), env.Resolve(elem)))
+				return Bool(false)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.Equal(t, []Term{
+				pair.Apply(Integer(1), NewAtom("a")),
+				pair.Apply(Integer(2), NewAtom("b")),
+			}, results)
 		})
 	})
 
@@ -7117,9 +8889,30 @@ func TestNth1(t *testing.T) {
 			})
 		})
 
-		t.Run("list is an improper list", func(t *testing.T) {
-			_, err := Nth1(nil, Integer(2), PartialList(NewVariable(), NewAtom("a")), NewVariable(), Success, nil).Force(context.Background())
-			assert.Equal(t, InstantiationError(nil), err)
+		t.Run("list is a partial list", func(t *testing.T) {
+			t.Run("n is within the instantiated prefix", func(t *testing.T) {
+				ok, err := Nth1(nil, Integer(1), PartialList(NewVariable(), NewAtom("a")), NewAtom("a"), Success, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			})
+
+			t.Run("n is beyond the instantiated prefix", func(t *testing.T) {
+				l, elem := NewVariable(), NewVariable()
+				ok, err := Nth1(nil, Integer(3), l, elem, func(env *Env) *Promise {
+					iter := ListIterator{List: l, Env: env, AllowPartial: true}
+					assert.True(t, iter.Next())
+					assert.True(t, iter.Next())
+					assert.True(t, iter.Next())
+					assert.Equal(t, elem, env.Resolve(iter.Current()))
+					assert.False(t, iter.Next())
+					assert.NoError(t, iter.Err())
+					_, ok := env.Resolve(iter.Suffix()).(Variable)
+					assert.True(t, ok)
+					return Bool(true)
+				}, NewEnv().bind(l, PartialList(NewVariable(), NewAtom("a")))).Force(context.Background())
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			})
 		})
 	})
 
@@ -7129,6 +8922,85 @@ func TestNth1(t *testing.T) {
 	})
 }
 
+func TestLast(t *testing.T) {
+	x := NewVariable()
+
+	tests := []struct {
+		title      string
+		list, elem Term
+		ok         bool
+		err        error
+		env        map[Variable]Term
+	}{
+		{title: "last([a, b, c], X).", list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), elem: x, ok: true, env: map[Variable]Term{
+			x: NewAtom("c"),
+		}},
+		{title: "last([a, b, c], b).", list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), elem: NewAtom("b"), ok: false},
+		{title: "last([], X).", list: List(), elem: x, ok: false},
+		{title: "last([a|_], X).", list: PartialList(NewVariable(), NewAtom("a")), elem: x, ok: false},
+		{title: "last(_, X).", list: NewVariable(), elem: x, ok: false},
+		{title: "last(foo, X).", list: NewAtom("foo"), elem: x, err: typeError(validTypeList, NewAtom("foo"), nil)},
+		{title: "last([a|b], X).", list: PartialList(NewAtom("b"), NewAtom("a")), elem: x, err: typeError(validTypeList, PartialList(NewAtom("b"), NewAtom("a")), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := Last(nil, tt.list, tt.elem, func(env *Env) *Promise {
+				for k, v := range tt.env {
+					assert.Equal(t, v, env.Resolve(k))
+				}
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	x, y := NewVariable(), NewVariable()
+
+	tests := []struct {
+		title          string
+		list, reversed Term
+		ok             bool
+		err            error
+		env            map[Variable]Term
+	}{
+		{title: "reverse([a, b, c], X).", list: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), reversed: x, ok: true, env: map[Variable]Term{
+			x: List(NewAtom("c"), NewAtom("b"), NewAtom("a")),
+		}},
+		{title: "reverse([], X).", list: List(), reversed: x, ok: true, env: map[Variable]Term{
+			x: List(),
+		}},
+		{title: "reverse(X, [a, b, c]).", list: x, reversed: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ok: true, env: map[Variable]Term{
+			x: List(NewAtom("c"), NewAtom("b"), NewAtom("a")),
+		}},
+		{title: "reverse([X|Y], [a, b, c]).", list: PartialList(y, x), reversed: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ok: true, env: map[Variable]Term{
+			x: NewAtom("c"),
+			y: List(NewAtom("b"), NewAtom("a")),
+		}},
+		{title: "reverse([a, b], [a, b]).", list: List(NewAtom("a"), NewAtom("b")), reversed: List(NewAtom("a"), NewAtom("b")), ok: false},
+		{title: "reverse(X, Y).", list: x, reversed: y, ok: false},
+		{title: "reverse([a|_], [b|_]).", list: PartialList(NewVariable(), NewAtom("a")), reversed: PartialList(NewVariable(), NewAtom("b")), ok: false},
+		{title: "reverse(foo, X).", list: NewAtom("foo"), reversed: x, err: typeError(validTypeList, NewAtom("foo"), nil)},
+		{title: "reverse(X, foo).", list: x, reversed: NewAtom("foo"), err: typeError(validTypeList, NewAtom("foo"), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := Reverse(nil, tt.list, tt.reversed, func(env *Env) *Promise {
+				for k, v := range tt.env {
+					assert.Equal(t, v, env.simplify(k))
+				}
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestSucc(t *testing.T) {
 	t.Run("x is a variable", func(t *testing.T) {
 		t.Run("s is a variable", func(t *testing.T) {
@@ -7207,6 +9079,50 @@ func TestSucc(t *testing.T) {
 		_, err := Succ(nil, Float(0), NewVariable(), Success, nil).Force(context.Background())
 		assert.Equal(t, typeError(validTypeInteger, Float(0), nil), err)
 	})
+
+	t.Run("bound variables", func(t *testing.T) {
+		x, s := NewVariable(), NewVariable()
+		env := NewEnv().bind(x, Integer(1)).bind(s, Integer(2))
+		ok, err := Succ(nil, x, s, Success, env).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestPlus(t *testing.T) {
+	x := NewVariable()
+
+	tests := []struct {
+		title   string
+		x, y, z Term
+		ok      bool
+		err     error
+		result  Term
+	}{
+		{title: "plus(1, 2, X).", x: Integer(1), y: Integer(2), z: x, ok: true, result: Integer(3)},
+		{title: "plus(1, X, 3).", x: Integer(1), y: x, z: Integer(3), ok: true, result: Integer(2)},
+		{title: "plus(X, 2, 3).", x: x, y: Integer(2), z: Integer(3), ok: true, result: Integer(1)},
+		{title: "plus(X, 5, 3).", x: x, y: Integer(5), z: Integer(3), ok: true, result: Integer(-2)},
+		{title: "plus(1, 2, 3).", x: Integer(1), y: Integer(2), z: Integer(3), ok: true},
+		{title: "plus(1, 2, 4).", x: Integer(1), y: Integer(2), z: Integer(4), ok: false},
+		{title: "plus(X, Y, 3).", x: x, y: NewVariable(), z: Integer(3), err: InstantiationError(nil)},
+		{title: "plus(1.0, 2, X).", x: Float(1), y: Integer(2), z: x, err: typeError(validTypeInteger, Float(1), nil)},
+		{title: "plus(a, X, 3).", x: NewAtom("a"), y: x, z: Integer(3), err: typeError(validTypeInteger, NewAtom("a"), nil)},
+		{title: "plus(maxInt, 1, X).", x: Integer(math.MaxInt64), y: Integer(1), z: x, err: evaluationError(exceptionalValueIntOverflow, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			ok, err := Plus(nil, tt.x, tt.y, tt.z, func(env *Env) *Promise {
+				if tt.result != nil {
+					assert.Equal(t, tt.result, env.Resolve(x))
+				}
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+		})
+	}
 }
 
 func TestLength(t *testing.T) {
@@ -7429,6 +9345,83 @@ func TestNegation(t *testing.T) {
 	assert.Equal(t, e, err)
 }
 
+func TestSoftCut(t *testing.T) {
+	var vm VM
+	vm.Register2(atomEqual, Unify)
+	vm.Register1(NewAtom("p"), func(_ *VM, x Term, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return Unify(&vm, x, Integer(1), k, env)
+		}, func(context.Context) *Promise {
+			return Unify(&vm, x, Integer(2), k, env)
+		})
+	})
+	vm.Register0(atomFail, func(*VM, Cont, *Env) *Promise {
+		return Bool(false)
+	})
+
+	t.Run("cond succeeds", func(t *testing.T) {
+		x, y := NewVariable(), NewVariable()
+		var xs []Term
+		ok, err := SoftCut(&vm, NewAtom("p").Apply(x), atomEqual.Apply(y, NewAtom("then")), atomEqual.Apply(y, NewAtom("else")), func(env *Env) *Promise {
+			xs = append(xs, env.Resolve(x))
+			assert.Equal(t, NewAtom("then"), env.Resolve(y))
+			return Bool(false)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, []Term{Integer(1), Integer(2)}, xs)
+	})
+
+	t.Run("cond fails", func(t *testing.T) {
+		y := NewVariable()
+		ok, err := SoftCut(&vm, atomFail, atomEqual.Apply(y, NewAtom("then")), atomEqual.Apply(y, NewAtom("else")), func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("else"), env.Resolve(y))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestForAll(t *testing.T) {
+	e := errors.New("failed")
+
+	var vm VM
+	vm.Register2(atomEqual, Unify)
+	vm.Register1(NewAtom("p"), func(_ *VM, x Term, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return Unify(&vm, x, Integer(1), k, env)
+		}, func(context.Context) *Promise {
+			return Unify(&vm, x, Integer(2), k, env)
+		})
+	})
+	vm.Register1(NewAtom("small"), func(_ *VM, x Term, k Cont, env *Env) *Promise {
+		if n, ok := env.Resolve(x).(Integer); ok && n < 3 {
+			return k(env)
+		}
+		return Bool(false)
+	})
+	vm.Register0(atomError, func(*VM, Cont, *Env) *Promise {
+		return Error(e)
+	})
+
+	x := NewVariable()
+
+	ok, err := ForAll(&vm, NewAtom("p").Apply(x), NewAtom("small").Apply(x), func(env *Env) *Promise {
+		assert.Equal(t, x, env.Resolve(x))
+		return Bool(true)
+	}, nil).Force(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ForAll(&vm, NewAtom("p").Apply(x), atomEqual.Apply(x, Integer(1)), Success, nil).Force(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = ForAll(&vm, NewAtom("p").Apply(x), atomError, Success, nil).Force(context.Background())
+	assert.Equal(t, e, err)
+}
+
 func TestAppend(t *testing.T) {
 	xs, ys, zs := NewVariable(), NewVariable(), NewVariable()
 	tests := []struct {
@@ -7454,6 +9447,13 @@ func TestAppend(t *testing.T) {
 			{xs: List(NewAtom("a"), NewAtom("b")), ys: List(NewAtom("c"))},
 			{xs: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ys: List()},
 		}},
+		{title: `append([a|Xs], Ys, [a,b]).`, xs: PartialList(xs, NewAtom("a")), ys: ys, zs: List(NewAtom("a"), NewAtom("b")), ok: true, env: []map[Variable]Term{
+			{xs: List(), ys: List(NewAtom("b"))},
+			{xs: List(NewAtom("b")), ys: List()},
+		}},
+		{title: `append(Xs, [c], [a,b,c]).`, xs: xs, ys: List(NewAtom("c")), zs: List(NewAtom("a"), NewAtom("b"), NewAtom("c")), ok: true, env: []map[Variable]Term{
+			{xs: List(NewAtom("a"), NewAtom("b"))},
+		}},
 	}
 
 	for _, tt := range tests {
@@ -7470,6 +9470,27 @@ func TestAppend(t *testing.T) {
 			assert.Equal(t, tt.err, err)
 		})
 	}
+
+	t.Run("append(Xs, Ys, Zs).", func(t *testing.T) {
+		var n int
+		ok, err := Append(nil, xs, ys, zs, func(env *Env) *Promise {
+			var elems []Term
+			iter := ListIterator{List: xs, Env: env}
+			for iter.Next() {
+				elems = append(elems, iter.Current())
+			}
+			assert.NoError(t, iter.Err())
+			assert.Len(t, elems, n)
+
+			_, ok := env.Unify(zs, PartialList(ys, elems...))
+			assert.True(t, ok)
+
+			n++
+			return Bool(n == 3)
+		}, nil).Force(context.Background())
+		assert.True(t, ok)
+		assert.NoError(t, err)
+	})
 }
 
 func Test_variant(t *testing.T) {
@@ -7507,6 +9528,11 @@ func Test_variant(t *testing.T) {
 			t2:     f.Apply(x, Integer(0)),
 			result: false,
 		},
+		{
+			t1:     f.Apply(a, b),
+			t2:     f.Apply(x, x),
+			result: false,
+		},
 		{
 			t1:     f.Apply(a, b),
 			t2:     g.Apply(x, y),
@@ -7573,6 +9599,15 @@ func (m *mockWriter) Write(p []byte) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+// vmWithOperators returns a VM which knows only the given operators.
+func vmWithOperators(ops ...operator) *VM {
+	vm := VM{operators: operators{}}
+	for _, op := range ops {
+		vm.operators.define(op.priority, op.specifier, op.name)
+	}
+	return &vm
+}
+
 func setMemFree(n int64) func() {
 	if n <= 0 {
 		return func() {}
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 )
 
 type userDefined struct {
@@ -10,19 +11,133 @@ type userDefined struct {
 	dynamic       bool
 	multifile     bool
 	discontiguous bool
+	frozen        bool
 
 	// 7.4.3 says "If no clauses are defined for a procedure indicated by a directive ... then the procedure shall exist but have no clauses."
 	clauses
+
+	// index narrows down the clauses to try once the procedure is frozen.
+	index *firstArgIndex
+
+	stats predicateStats
+}
+
+// predicateStats is the counters of a user-defined predicate. They're updated atomically since queries may run concurrently.
+type predicateStats struct {
+	calls, redos int64
+}
+
+func (u *userDefined) call(vm *VM, args []Term, k Cont, env *Env) *Promise {
+	return u.exec(vm, args, k, env, nil)
+}
+
+// exec calls the procedure. If it's a last call, lc is the chain of last calls it belongs to.
+func (u *userDefined) exec(vm *VM, args []Term, k Cont, env *Env, lc *lastCall) *Promise {
+	atomic.AddInt64(&u.stats.calls, 1)
+	cs := u.clauses
+	if u.index != nil {
+		cs = u.index.lookup(args[0], env)
+	}
+	return cs.exec(vm, args, k, env, u, lc)
+}
+
+// freeze makes the procedure immutable and indexes its clauses by the first argument if any.
+func (u *userDefined) freeze(arity Integer) {
+	u.dynamic = false
+	u.frozen = true
+	if arity > 0 {
+		u.index = newFirstArgIndex(u.clauses)
+	}
+}
+
+// firstArgIndex is a set of clauses keyed by the principal functor of their first arguments.
+type firstArgIndex struct {
+	all     clauses
+	rest    clauses // the clauses of which the first argument is not indexable e.g. a variable.
+	buckets map[interface{}]clauses
+}
+
+func newFirstArgIndex(cs clauses) *firstArgIndex {
+	idx := firstArgIndex{
+		all:     cs,
+		buckets: map[interface{}]clauses{},
+	}
+	keys := make([]interface{}, len(cs))
+	for i, c := range cs {
+		head := rulify(c.raw, nil).(Compound).Arg(0).(Compound)
+		if key, ok := indexKey(head.Arg(0), nil); ok {
+			keys[i] = key
+			idx.buckets[key] = nil
+		}
+	}
+	for i, c := range cs {
+		if key := keys[i]; key != nil {
+			idx.buckets[key] = append(idx.buckets[key], c)
+			continue
+		}
+		for key := range idx.buckets {
+			idx.buckets[key] = append(idx.buckets[key], c)
+		}
+		idx.rest = append(idx.rest, c)
+	}
+	return &idx
+}
+
+// lookup returns the clauses which might match with the first argument arg.
+func (idx *firstArgIndex) lookup(arg Term, env *Env) clauses {
+	key, ok := indexKey(arg, env)
+	if !ok {
+		return idx.all
+	}
+	if cs, ok := idx.buckets[key]; ok {
+		return cs
+	}
+	return idx.rest
+}
+
+func indexKey(t Term, env *Env) (interface{}, bool) {
+	switch t := env.Resolve(t).(type) {
+	case Atom, Integer, Float:
+		return t, true
+	case Compound:
+		return procedureIndicator{name: t.Functor(), arity: Integer(t.Arity())}, true
+	default:
+		return nil, false
+	}
 }
 
 type clauses []clause
 
 func (cs clauses) call(vm *VM, args []Term, k Cont, env *Env) *Promise {
+	return cs.exec(vm, args, k, env, nil, nil)
+}
+
+// exec tries the clauses from the first to the last. If u is not nil, it counts the retries and records the clauses to the proof tree.
+// If lc is nil, the call starts a new chain of last calls.
+func (cs clauses) exec(vm *VM, args []Term, k Cont, env *Env, u *userDefined, lc *lastCall) *Promise {
+	if lc == nil {
+		lc = newLastCall(env)
+	}
 	var p *Promise
 	ks := make([]func(context.Context) *Promise, len(cs))
 	for i := range cs {
 		i, c := i, cs[i]
 		ks[i] = func(context.Context) *Promise {
+			env := env
+			if u != nil {
+				if i > 0 {
+					atomic.AddInt64(&u.stats.redos, 1)
+					if vm.OnRedo != nil {
+						vm.OnRedo(c.pi.name, args, env)
+					}
+				}
+				if vm.CaptureProofTree {
+					env = proveBy(c.raw, env)
+				}
+			}
+			if !c.mayMatch(args, env) {
+				return Bool(false)
+			}
 			vars := make([]Variable, len(c.vars))
 			for i := range vars {
 				vars[i] = NewVariable()
@@ -36,6 +151,7 @@ func (cs clauses) call(vm *VM, args []Term, k Cont, env *Env) *Promise {
 				astack:    List(),
 				env:       env,
 				cutParent: p,
+				lastCall:  lc,
 			})
 		}
 	}
@@ -55,6 +171,7 @@ func compile(t Term, env *Env) (clauses, error) {
 				return nil, typeError(validTypeCallable, body, env)
 			}
 			c.raw = t
+			c.alternative = len(cs) > 0
 			cs = append(cs, c)
 		}
 		return cs, nil
@@ -71,6 +188,48 @@ type clause struct {
 	xrTable  []Term
 	vars     []Variable
 	bytecode bytecode
+
+	// alternative is set if the clause is compiled from the second or later alternative in the body of raw.
+	alternative bool
+}
+
+// mayMatch cheaply checks if the head of the clause might unify with args by comparing the principal functors.
+// It reads the head arguments off the bytecode so that obviously non-matching clauses don't allocate their variables.
+func (c *clause) mayMatch(args []Term, env *Env) bool {
+	var i, depth int
+	for _, inst := range c.bytecode {
+		var key interface{}
+		switch inst.opcode {
+		case opEnter, opExit:
+			return true
+		case opPop:
+			depth--
+			if depth == 0 {
+				i++
+			}
+			continue
+		case opConst:
+			key, _ = indexKey(c.xrTable[inst.operand], nil)
+		case opFunctor:
+			key = c.xrTable[inst.operand]
+		case opList, opPartial:
+			key = procedureIndicator{name: atomDot, arity: 2}
+		}
+		if depth == 0 && key != nil {
+			if k, ok := indexKey(args[i], env); ok && k != key {
+				return false
+			}
+		}
+		switch inst.opcode {
+		case opFunctor, opList, opPartial:
+			depth++
+		default:
+			if depth == 0 {
+				i++
+			}
+		}
+	}
+	return true
 }
 
 func compileClause(head Term, body Term, env *Env) (clause, error) {
@@ -155,7 +314,7 @@ func (c *clause) compileArg(a Term, env *Env) {
 			c.compileArg(a.Arg(i), env)
 		}
 		c.bytecode = append(c.bytecode, instruction{opcode: opPop})
-	default:
+	default: // Any other terms including the ones defined outside of this package are treated as atomic.
 		c.bytecode = append(c.bytecode, instruction{opcode: opConst, operand: c.xrOffset(a)})
 	}
 }
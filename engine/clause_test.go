@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// customAtomic is an atomic term type which the engine doesn't know about.
+type customAtomic string
+
+func (c customAtomic) WriteTerm(w io.Writer, _ *WriteOptions, _ *Env) error {
+	_, err := fmt.Fprintf(w, "<%s>", string(c))
+	return err
+}
+
+func (c customAtomic) Compare(t Term, env *Env) int {
+	return CompareAtomic(c, t, func(a, b customAtomic) int {
+		switch {
+		case a > b:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 0
+		}
+	}, env)
+}
+
+func TestCompile(t *testing.T) {
+	t.Run("custom atomic", func(t *testing.T) {
+		cs, err := compile(NewAtom("foo").Apply(customAtomic("bar")), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, clauses{
+			{
+				pi:      procedureIndicator{name: NewAtom("foo"), arity: 1},
+				raw:     NewAtom("foo").Apply(customAtomic("bar")),
+				xrTable: []Term{customAtomic("bar")},
+				bytecode: bytecode{
+					{opcode: opConst, operand: 0},
+					{opcode: opExit},
+				},
+			},
+		}, cs)
+
+		vm := VM{procedures: map[procedureIndicator]procedure{
+			{name: NewAtom("foo"), arity: 1}: &userDefined{clauses: cs},
+		}}
+
+		x := NewVariable()
+		ok, err := Call(&vm, NewAtom("foo").Apply(x), func(env *Env) *Promise {
+			assert.Equal(t, customAtomic("bar"), env.Resolve(x))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = Call(&vm, NewAtom("foo").Apply(customAtomic("baz")), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("partial list in head", func(t *testing.T) {
+		h, x := NewVariable(), NewVariable()
+		cs, err := compile(NewAtom("first").Apply(PartialList(NewVariable(), h), h), nil)
+		assert.NoError(t, err)
+		assert.Len(t, cs, 1)
+		assert.Equal(t, opPartial, cs[0].bytecode[0].opcode)
+
+		vm := VM{procedures: map[procedureIndicator]procedure{
+			{name: NewAtom("first"), arity: 2}: &userDefined{clauses: cs},
+		}}
+
+		ok, err := Call(&vm, NewAtom("first").Apply(List(NewAtom("a"), NewAtom("b")), x), func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("a"), env.Resolve(x))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = Call(&vm, NewAtom("first").Apply(List(), NewVariable()), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestClause_mayMatch(t *testing.T) {
+	f, x := NewAtom("f"), NewVariable()
+	cs, err := compile(NewAtom("foo").Apply(f.Apply(x, NewAtom("a")), List(x), NewAtom("b"), x, Integer(1)), nil)
+	assert.NoError(t, err)
+	c := cs[0]
+
+	tests := []struct {
+		title string
+		args  []Term
+		ok    bool
+	}{
+		{title: "variables", args: []Term{NewVariable(), NewVariable(), NewVariable(), NewVariable(), NewVariable()}, ok: true},
+		{title: "same functors", args: []Term{f.Apply(NewAtom("c"), NewAtom("d")), List(NewAtom("c"), NewAtom("d")), NewAtom("b"), NewAtom("e"), Integer(1)}, ok: true},
+		{title: "different compound", args: []Term{f.Apply(NewAtom("a")), NewVariable(), NewVariable(), NewVariable(), NewVariable()}, ok: false},
+		{title: "not a list", args: []Term{NewVariable(), List(), NewVariable(), NewVariable(), NewVariable()}, ok: false},
+		{title: "different atom", args: []Term{NewVariable(), NewVariable(), NewAtom("c"), NewVariable(), NewVariable()}, ok: false},
+		{title: "different integer", args: []Term{NewVariable(), NewVariable(), NewVariable(), NewVariable(), Integer(2)}, ok: false},
+		{title: "float", args: []Term{NewVariable(), NewVariable(), NewVariable(), NewVariable(), Float(1)}, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.ok, c.mayMatch(tt.args, nil))
+		})
+	}
+}
+
+func BenchmarkClauses_Call(b *testing.B) {
+	var cs clauses
+	for i := 0; i < 100; i++ {
+		c, err := compile(NewAtom("foo").Apply(Integer(i), NewAtom("x")), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		cs = append(cs, c...)
+	}
+
+	var vm VM
+	args := []Term{Integer(99), NewVariable()}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cs.call(&vm, args, Success, nil).Force(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -6,19 +6,54 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 )
 
 // Compound is a Prolog compound.
+// Lists and partial lists have their own compact representations but they all behave as compounds through this interface.
 type Compound interface {
 	Term
+	// Functor returns the name of the compound.
 	Functor() Atom
+	// Arity returns the number of the arguments.
 	Arity() int
+	// Arg returns the nth argument (0-origin).
 	Arg(n int) Term
 }
 
+// NewCompound returns a compound of functor and args in the most efficient representation.
+// A list cell whose tail is a list is represented as a list. It returns functor if args are empty.
+func NewCompound(functor Atom, args ...Term) Term {
+	if functor != atomDot || len(args) != 2 {
+		return functor.Apply(args...)
+	}
+
+	switch t := args[1].(type) {
+	case Atom:
+		if t == atomEmptyList {
+			return list{args[0]}
+		}
+	case list:
+		return append(list{args[0]}, t...)
+	case *partial:
+		if l, ok := t.Compound.(list); ok {
+			return &partial{Compound: append(list{args[0]}, l...), tail: t.tail}
+		}
+	}
+	return functor.Apply(args...)
+}
+
 // WriteCompound outputs the Compound to an io.Writer.
 func WriteCompound(w io.Writer, c Compound, opts *WriteOptions, env *Env) error {
+	if opts.maxDepth > 0 && opts.depth >= opts.maxDepth {
+		_, err := w.Write([]byte("..."))
+		return err
+	}
+	o := *opts
+	o.depth++
+	opts = &o
+
 	ok, err := writeCompoundVisit(w, c, opts)
 	if err != nil || ok {
 		return err
@@ -83,6 +118,12 @@ func writeCompoundList(w io.Writer, c Compound, opts *WriteOptions, env *Env) er
 	_ = c.Arg(0).WriteTerm(&ew, opts, env)
 	iter := ListIterator{List: c.Arg(1), Env: env}
 	for iter.Next() {
+		// Each element counts against the depth budget.
+		opts = opts.withDepth(opts.depth + 1)
+		if opts.maxDepth > 0 && opts.depth >= opts.maxDepth {
+			_, _ = fmt.Fprint(&ew, "|...]")
+			return ew.err
+		}
 		_, _ = fmt.Fprint(&ew, ",")
 		_ = iter.Current().WriteTerm(&ew, opts, env)
 	}
@@ -266,6 +307,18 @@ func (ew *errWriter) Write(p []byte) (int, error) {
 type compound struct {
 	functor Atom
 	args    []Term
+
+	// ground is set to 1 once the compound is found to contain no variables at all.
+	// Since terms are immutable, such a compound stays ground however the bindings change.
+	ground uint32
+}
+
+func (c *compound) knownGround() bool {
+	return atomic.LoadUint32(&c.ground) == 1
+}
+
+func (c *compound) markGround() {
+	atomic.StoreUint32(&c.ground, 1)
 }
 
 func (c *compound) WriteTerm(w io.Writer, opts *WriteOptions, env *Env) error {
@@ -297,6 +350,14 @@ func Cons(car, cdr Term) Term {
 	return atomDot.Apply(car, cdr)
 }
 
+// Rule returns a clause of head and the conjunction of goals. It returns head itself if there's no goals.
+func Rule(head Term, goals ...Term) Term {
+	if len(goals) == 0 {
+		return head
+	}
+	return atomIf.Apply(head, seq(atomComma, goals...))
+}
+
 type list []Term
 
 func (l list) WriteTerm(w io.Writer, opts *WriteOptions, env *Env) error {
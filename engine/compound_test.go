@@ -37,6 +37,14 @@ func TestWriteCompound(t *testing.T) {
 		{title: "list-ish", term: PartialList(NewAtom(`rest`), NewAtom(`a`), NewAtom(`b`)), output: `[a,b|rest]`},
 		{title: "circular list", term: l, output: `[a,b,a|...]`},
 		{title: "curly brackets", term: atomEmptyBlock.Apply(NewAtom(`foo`)), output: `{foo}`},
+		{title: "list in curly brackets", term: atomEmptyBlock.Apply(List(NewAtom(`a`), NewAtom(`b`))), output: `{[a,b]}`},
+		{title: "curly brackets in list", term: List(atomEmptyBlock.Apply(NewAtom(`a`)), atomEmptyBlock.Apply(NewAtom(`b`))), output: `[{a},{b}]`},
+		{title: "nested curly brackets", term: atomEmptyBlock.Apply(atomEmptyBlock.Apply(List(atomEmptyBlock.Apply(NewAtom(`a`))))), output: `{{[{a}]}}`},
+		{title: "max_depth", term: f.Apply(NewAtom(`g`).Apply(NewAtom(`h`).Apply(NewAtom(`a`)))), opts: WriteOptions{maxDepth: 2}, output: `f(g(...))`},
+		{title: "max_depth, list", term: List(Integer(1), Integer(2), Integer(3), Integer(4)), opts: WriteOptions{maxDepth: 3}, output: `[1,2|...]`},
+		{title: "max_depth, list in compound", term: f.Apply(List(Integer(1), Integer(2), Integer(3), Integer(4))), opts: WriteOptions{maxDepth: 3}, output: `f([1|...])`},
+		{title: "max_depth, short list", term: List(Integer(1), Integer(2)), opts: WriteOptions{maxDepth: 3}, output: `[1,2]`},
+		{title: "max_depth, unlimited", term: List(Integer(1), Integer(2), Integer(3), Integer(4)), opts: WriteOptions{maxDepth: 0}, output: `[1,2,3,4]`},
 		{title: "fx", term: atomIf.Apply(atomIf.Apply(NewAtom(`foo`))), opts: WriteOptions{ops: ops, priority: 1201}, output: `:- (:-foo)`},
 		{title: "fy", term: atomNegation.Apply(atomMinus.Apply(atomNegation.Apply(NewAtom(`foo`)))), opts: WriteOptions{ops: ops, priority: 1201}, output: `\+ - (\+foo)`},
 		{title: "xf", term: NewAtom(`-:`).Apply(NewAtom(`-:`).Apply(NewAtom(`foo`))), opts: WriteOptions{ops: ops, priority: 1201}, output: `(foo-:)-:`},
@@ -46,6 +54,9 @@ func TestWriteCompound(t *testing.T) {
 		{title: "xfy", term: atomComma.Apply(Integer(2), atomBar.Apply(Integer(2), Integer(2))), opts: WriteOptions{ops: ops, priority: 1201}, output: `2,(2|2)`},
 		{title: "ignore_ops(false)", term: atomPlus.Apply(Integer(2), Integer(-2)), opts: WriteOptions{ignoreOps: false, ops: ops, priority: 1201}, output: `2+ -2`},
 		{title: "ignore_ops(true)", term: atomPlus.Apply(Integer(2), Integer(-2)), opts: WriteOptions{ignoreOps: true, ops: ops, priority: 1201}, output: `+(2,-2)`},
+		{title: "ignore_ops(true), list", term: List(NewAtom("a"), NewAtom("b")), opts: WriteOptions{ignoreOps: true, quoted: true, ops: ops, priority: 1201}, output: `'.'(a,'.'(b,[]))`},
+		{title: "ignore_ops(true), partial list", term: PartialList(NewAtom("c"), NewAtom("a"), NewAtom("b")), opts: WriteOptions{ignoreOps: true, quoted: true, ops: ops, priority: 1201}, output: `'.'(a,'.'(b,c))`},
+		{title: "ignore_ops(true), curly brackets", term: atomEmptyBlock.Apply(NewAtom("a")), opts: WriteOptions{ignoreOps: true, quoted: true, ops: ops, priority: 1201}, output: `{}(a)`},
 		{title: "number_vars(false)", term: f.Apply(atomVar.Apply(Integer(0)), atomVar.Apply(Integer(1)), atomVar.Apply(Integer(25)), atomVar.Apply(Integer(26)), atomVar.Apply(Integer(27))), opts: WriteOptions{quoted: true, numberVars: false, ops: ops, priority: 1201}, output: `f('$VAR'(0),'$VAR'(1),'$VAR'(25),'$VAR'(26),'$VAR'(27))`},
 		{title: "number_vars(true)", term: f.Apply(atomVar.Apply(Integer(0)), atomVar.Apply(Integer(1)), atomVar.Apply(Integer(25)), atomVar.Apply(Integer(26)), atomVar.Apply(Integer(27))), opts: WriteOptions{quoted: true, numberVars: true, ops: ops, priority: 1201}, output: `f(A,B,Z,A1,B1)`},
 		{title: "prefix: spacing between operators", term: atomAsterisk.Apply(NewAtom("a"), atomMinus.Apply(NewAtom("b"))), opts: WriteOptions{ops: ops, priority: 1201}, output: `a* -b`},
@@ -128,6 +139,66 @@ func TestPartialList(t *testing.T) {
 	}
 }
 
+func TestCompound_uniform(t *testing.T) {
+	a, b, c := NewAtom("a"), NewAtom("b"), NewAtom("c")
+	x := NewVariable()
+	l := List(a, b, c)
+	p := PartialList(x, a)
+	s := atomDot.Apply(a, atomDot.Apply(b, atomDot.Apply(c, atomEmptyList)))
+
+	env, ok := NewEnv().Unify(l, p)
+	assert.True(t, ok)
+	assert.Equal(t, list{b, c}, env.Resolve(x))
+
+	env, ok = env.Unify(p, s)
+	assert.True(t, ok)
+
+	var buf bytes.Buffer
+	for _, t1 := range []Term{l, p, s} {
+		for _, t2 := range []Term{l, p, s} {
+			assert.Equal(t, 0, t1.Compare(t2, env))
+		}
+
+		buf.Reset()
+		assert.NoError(t, t1.WriteTerm(&buf, &defaultWriteOptions, env))
+		assert.Equal(t, `[a,b,c]`, buf.String())
+
+		var elems []Term
+		iter := ListIterator{List: t1, Env: env}
+		for iter.Next() {
+			elems = append(elems, iter.Current())
+		}
+		assert.NoError(t, iter.Err())
+		assert.Equal(t, []Term{a, b, c}, elems)
+	}
+}
+
+func TestNewCompound(t *testing.T) {
+	a, b, x := NewAtom("a"), NewAtom("b"), Term(NewVariable())
+
+	tests := []struct {
+		title   string
+		functor Atom
+		args    []Term
+		term    Term
+	}{
+		{title: "atom", functor: a, args: nil, term: a},
+		{title: "compound", functor: NewAtom("f"), args: []Term{a, b}, term: &compound{functor: NewAtom("f"), args: []Term{a, b}}},
+		{title: "list", functor: atomDot, args: []Term{a, List(b)}, term: list{a, b}},
+		{title: "singleton list", functor: atomDot, args: []Term{a, atomEmptyList}, term: list{a}},
+		{title: "partial list", functor: atomDot, args: []Term{a, PartialList(x, b)}, term: &partial{Compound: list{a, b}, tail: &x}},
+		{title: "list cell", functor: atomDot, args: []Term{a, x}, term: &compound{functor: atomDot, args: []Term{a, x}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.term, NewCompound(tt.functor, tt.args...))
+		})
+	}
+
+	assert.Equal(t, List(a, b), NewCompound(atomDot, a, List(b)))
+}
+
 func TestEnv_Set(t *testing.T) {
 	env := NewEnv()
 	assert.Equal(t, List(), env.set())
@@ -136,6 +207,12 @@ func TestEnv_Set(t *testing.T) {
 	assert.Equal(t, List(NewAtom("a"), NewAtom("b"), NewAtom("c")), env.set(NewAtom("c"), NewAtom("b"), NewAtom("a")))
 }
 
+func TestRule(t *testing.T) {
+	assert.Equal(t, NewAtom("foo"), Rule(NewAtom("foo")))
+	assert.Equal(t, atomIf.Apply(NewAtom("foo"), NewAtom("a")), Rule(NewAtom("foo"), NewAtom("a")))
+	assert.Equal(t, atomIf.Apply(NewAtom("foo"), atomComma.Apply(NewAtom("a"), atomComma.Apply(NewAtom("b"), NewAtom("c")))), Rule(NewAtom("foo"), NewAtom("a"), NewAtom("b"), NewAtom("c")))
+}
+
 func TestSeq(t *testing.T) {
 	assert.Equal(t, NewAtom("a"), seq(atomComma, NewAtom("a")))
 	assert.Equal(t, &compound{
@@ -160,6 +237,29 @@ func TestSeq(t *testing.T) {
 	}, seq(atomComma, NewAtom("a"), NewAtom("b"), NewAtom("c")))
 }
 
+func TestCharList_unify(t *testing.T) {
+	a, b, c := NewAtom("a"), NewAtom("b"), NewAtom("c")
+	x := NewVariable()
+
+	_, ok := NewEnv().Unify(CharList("abc"), List(a, b, c))
+	assert.True(t, ok)
+	_, ok = NewEnv().Unify(CodeList("abc"), List(Integer('a'), Integer('b'), Integer('c')))
+	assert.True(t, ok)
+	_, ok = NewEnv().Unify(CharList("abc"), CodeList("abc"))
+	assert.False(t, ok)
+
+	env, ok := NewEnv().Unify(CharList("abc"), PartialList(x, a))
+	assert.True(t, ok)
+	assert.Equal(t, 0, List(b, c).Compare(env.Resolve(x), env))
+
+	var buf bytes.Buffer
+	assert.NoError(t, CharList("abc").WriteTerm(&buf, &defaultWriteOptions, nil))
+	assert.Equal(t, "[a,b,c]", buf.String())
+	buf.Reset()
+	assert.NoError(t, CodeList("abc").WriteTerm(&buf, &defaultWriteOptions, nil))
+	assert.Equal(t, "[97,98,99]", buf.String())
+}
+
 func TestCharList(t *testing.T) {
 	assert.Equal(t, atomEmptyList, CharList(""))
 	assert.Equal(t, charList("abc"), CharList("abc"))
@@ -16,6 +16,14 @@ func newEnvKey(v Variable) envKey {
 	return k
 }
 
+// variable returns the variable of which the key is k.
+func (k envKey) variable() Variable {
+	if k/2 != 0 {
+		k *= -1
+	}
+	return Variable(k)
+}
+
 type color uint8
 
 const (
@@ -27,6 +35,7 @@ const (
 type Env struct {
 	// basically, this is Red-Black tree from Purely Functional Data Structures by Okazaki.
 	color       color
+	size        int32 // the number of the bindings in the tree.
 	left, right *Env
 	binding
 }
@@ -38,6 +47,7 @@ type binding struct {
 }
 
 var rootEnv = &Env{
+	size: 1,
 	binding: binding{
 		key:   newEnvKey(varContext),
 		value: rootContext,
@@ -87,17 +97,19 @@ func (e *Env) bind(v Variable, t Term) *Env {
 
 func (e *Env) insert(k envKey, v Term) *Env {
 	if e == nil {
-		return &Env{color: red, binding: binding{key: k, value: v}}
+		return &Env{color: red, size: 1, binding: binding{key: k, value: v}}
 	}
 	switch {
 	case k < e.key:
 		ret := *e
 		ret.left = e.left.insert(k, v)
+		ret.size = int32(1 + ret.left.len() + ret.right.len())
 		ret.balance()
 		return &ret
 	case k > e.key:
 		ret := *e
 		ret.right = e.right.insert(k, v)
+		ret.size = int32(1 + ret.left.len() + ret.right.len())
 		ret.balance()
 		return &ret
 	default:
@@ -160,12 +172,74 @@ func (e *Env) balance() {
 	}
 	*e = Env{
 		color:   red,
-		left:    &Env{color: black, left: a, right: b, binding: x},
-		right:   &Env{color: black, left: c, right: d, binding: z},
+		size:    e.size,
+		left:    &Env{color: black, size: int32(1 + a.len() + b.len()), left: a, right: b, binding: x},
+		right:   &Env{color: black, size: int32(1 + c.len() + d.len()), left: c, right: d, binding: z},
 		binding: y,
 	}
 }
 
+// len returns the number of the bindings.
+func (e *Env) len() int {
+	if e == nil {
+		return 0
+	}
+	return int(e.size)
+}
+
+// each calls f for every binding in the environment.
+func (e *Env) each(f func(b binding)) {
+	if e == nil {
+		return
+	}
+	e.left.each(f)
+	f(e.binding)
+	e.right.each(f)
+}
+
+// compact returns an environment with the bindings of the variables not newer than mark and the ones reachable from
+// them or roots. It also returns the number of the terms it went through.
+// If nothing but roots refers to the variables newer than mark, the other bindings are garbage.
+func (e *Env) compact(mark Variable, roots []Term) (*Env, int) {
+	var ret *Env
+	stack := append([]Term{}, roots...)
+	e.each(func(b binding) {
+		if v := b.key.variable(); v <= mark {
+			ret = ret.bind(v, b.value)
+			stack = append(stack, b.value)
+		}
+	})
+
+	var (
+		work int
+		seen = map[Variable]struct{}{}
+	)
+	for len(stack) > 0 {
+		var t Term
+		t, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		work++
+		switch t := t.(type) {
+		case Variable:
+			if t <= mark {
+				break
+			}
+			if _, ok := seen[t]; ok {
+				break
+			}
+			seen[t] = struct{}{}
+			if u, ok := e.lookup(t); ok {
+				ret = ret.bind(t, u)
+				stack = append(stack, u)
+			}
+		case Compound:
+			for i := 0; i < t.Arity(); i++ {
+				stack = append(stack, t.Arg(i))
+			}
+		}
+	}
+	return ret, work
+}
+
 // Resolve follows the variable chain and returns the first non-variable term or the last free variable.
 func (e *Env) Resolve(t Term) Term {
 	var stop []Variable
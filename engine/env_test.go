@@ -14,7 +14,9 @@ func TestEnv_Bind(t *testing.T) {
 	var env *Env
 	assert.Equal(t, &Env{
 		color: black,
+		size:  2,
 		left: &Env{
+			size: 1,
 			binding: binding{
 				key:   newEnvKey(a),
 				value: NewAtom("a"),
@@ -73,6 +75,25 @@ func TestEnv_Simplify(t *testing.T) {
 	assert.Equal(t, 2, suffix.Arity())
 }
 
+func TestEnv_Compact(t *testing.T) {
+	x := NewVariable()
+	mark := lastVariable()
+	a, b, c, d := NewVariable(), NewVariable(), NewVariable(), NewVariable()
+
+	env := NewEnv().
+		bind(x, NewAtom("f").Apply(a)).
+		bind(a, b).
+		bind(c, NewAtom("garbage")).
+		bind(d, Integer(1))
+	assert.Equal(t, 5, env.len())
+
+	env, _ = env.compact(mark, []Term{d})
+	assert.Equal(t, 4, env.len())
+	assert.Equal(t, NewAtom("f").Apply(b), env.simplify(x))
+	assert.Equal(t, Integer(1), env.Resolve(d))
+	assert.Equal(t, c, env.Resolve(c))
+}
+
 func TestContains(t *testing.T) {
 	var env *Env
 	assert.True(t, contains(NewAtom("a"), NewAtom("a"), env))
@@ -53,6 +53,7 @@ const (
 	validTypePredicateIndicator
 	validTypePair
 	validTypeFloat
+	validTypeVariable
 )
 
 var validTypeAtoms = [...]Atom{
@@ -71,6 +72,7 @@ var validTypeAtoms = [...]Atom{
 	validTypePredicateIndicator: atomPredicateIndicator,
 	validTypePair:               atomPair,
 	validTypeFloat:              atomFloat,
+	validTypeVariable:           atomVariable,
 }
 
 // Term returns an Atom for the validType.
@@ -111,6 +113,7 @@ const (
 	validDomainWriteOption
 
 	validDomainOrder
+	validDomainStyleName
 )
 
 var validDomainAtoms = [...]Atom{
@@ -132,6 +135,7 @@ var validDomainAtoms = [...]Atom{
 	validDomainStreamProperty:    atomStreamProperty,
 	validDomainWriteOption:       atomWriteOption,
 	validDomainOrder:             atomOrder,
+	validDomainStyleName:         atomStyleName,
 }
 
 // Term returns an Atom for the validDomain.
@@ -249,6 +253,7 @@ const (
 	flagMaxArity
 	flagMaxInteger
 	flagMinInteger
+	flagCyclicTerm
 )
 
 var flagAtoms = [...]Atom{
@@ -258,6 +263,7 @@ var flagAtoms = [...]Atom{
 	flagMaxArity:        atomMaxArity,
 	flagMaxInteger:      atomMaxInteger,
 	flagMinInteger:      atomMinInteger,
+	flagCyclicTerm:      atomCyclicTerm,
 }
 
 // Term returns an Atom for the flag.
@@ -278,11 +284,13 @@ const (
 	resourceFiniteMemory resource = iota
 
 	resourceMemory
+	resourceInferenceLimitExceeded
 )
 
 var resourceAtoms = [...]Atom{
-	resourceFiniteMemory: atomFiniteMemory,
-	resourceMemory:       atomMemory,
+	resourceFiniteMemory:           atomFiniteMemory,
+	resourceMemory:                 atomMemory,
+	resourceInferenceLimitExceeded: atomInferenceLimitExceeded,
 }
 
 // Term returns an Atom for the resource.
@@ -296,6 +304,11 @@ func resourceError(resource resource, env *Env) Exception {
 	return Exception{term: atomError.Apply(atomResourceError.Apply(resource.Term()), env.Resolve(varContext))}
 }
 
+// occursError creates a new exception that x and y are unifiable only without occurs check.
+func occursError(x, y Term, env *Env) Exception {
+	return NewException(atomError.Apply(atomOccursCheck.Apply(x, y), varContext), env)
+}
+
 // syntaxError creates a new syntax error exception.
 func syntaxError(err error, env *Env) Exception {
 	return NewException(atomError.Apply(atomSyntaxError.Apply(NewAtom(err.Error())), varContext), env)
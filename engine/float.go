@@ -2,6 +2,7 @@ package engine
 
 import (
 	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -14,9 +15,10 @@ func (f Float) number() {}
 // WriteTerm outputs the Float to an io.Writer.
 func (f Float) WriteTerm(w io.Writer, opts *WriteOptions, _ *Env) error {
 	ew := errWriter{w: w}
-	openClose := opts.left.name == atomMinus && opts.left.specifier.class() == operatorClassPrefix && f > 0
+	negative := math.Signbit(float64(f))
+	openClose := opts.left.name == atomMinus && opts.left.specifier.class() == operatorClassPrefix && !negative
 
-	if openClose || (f < 0 && opts.left != operator{}) {
+	if openClose || (negative && opts.left != operator{}) {
 		_, _ = ew.Write([]byte(" "))
 	}
 
@@ -59,7 +61,9 @@ func (f Float) Compare(t Term, env *Env) int {
 		default:
 			return 0
 		}
-	default: // Integer, Atom, custom atomic terms, Compound.
+	case Integer:
+		return -compareIntegerFloat(t, f)
+	default: // Atom, custom atomic terms, Compound.
 		return -1
 	}
 }
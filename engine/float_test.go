@@ -3,6 +3,7 @@ package engine
 import (
 	"bytes"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -21,6 +22,8 @@ func TestFloat_WriteTerm(t *testing.T) {
 		{title: "with e", f: 3.0e+100, output: `3.0e+100`},
 		{title: "positive following unary minus", f: 33.0, opts: WriteOptions{left: operator{specifier: operatorSpecifierFX, name: atomMinus}}, output: ` (33.0)`},
 		{title: "negative", f: -33.0, output: `-33.0`},
+		{title: "zero following unary minus", f: 0.0, opts: WriteOptions{left: operator{specifier: operatorSpecifierFX, name: atomMinus}}, output: ` (0.0)`},
+		{title: "negative zero following graphic operator", f: Float(math.Copysign(0, -1)), opts: WriteOptions{left: operator{specifier: operatorSpecifierXFX, name: atomEqual}}, output: ` -0.0`},
 		{title: "ambiguous e", f: 33.0, opts: WriteOptions{right: operator{name: NewAtom(`e`)}}, output: `33.0 `}, // So that it won't be 33.0e.
 	}
 
@@ -48,6 +51,8 @@ func TestFloat_Compare(t *testing.T) {
 		{title: `1.0 = 1.0`, f: Float(1), t: Float(1), o: 0},
 		{title: `1.0 < 2.0`, f: Float(1), t: Float(2), o: -1},
 		{title: `1.0 < 1`, f: Float(1), t: Integer(1), o: -1},
+		{title: `1.5 > 1`, f: Float(1.5), t: Integer(1), o: 1},
+		{title: `0.5 < 1`, f: Float(0.5), t: Integer(1), o: -1},
 		{title: `1.0 < a`, f: Float(1), t: NewAtom("a"), o: -1},
 		{title: `1.0 < f(a)`, f: Float(1), t: NewAtom("f").Apply(NewAtom("a")), o: -1},
 	}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+var varInference = NewVariable()
+
+// inferenceCounter counts the predicate calls against the limit.
+// Unlike proofFrame, it's mutable so that the calls are counted across backtracking.
+type inferenceCounter struct {
+	count, limit uint64
+	parent       *inferenceCounter
+}
+
+// WriteTerm outputs the inferenceCounter to an io.Writer.
+func (c *inferenceCounter) WriteTerm(w io.Writer, _ *WriteOptions, _ *Env) error {
+	_, err := fmt.Fprintf(w, "<inference>(%p)", c)
+	return err
+}
+
+// Compare compares the inferenceCounter with a Term.
+func (c *inferenceCounter) Compare(t Term, env *Env) int {
+	return CompareAtomic[*inferenceCounter](c, t, func(c *inferenceCounter, d *inferenceCounter) int {
+		switch x, y := uintptr(unsafe.Pointer(c)), uintptr(unsafe.Pointer(d)); {
+		case x > y:
+			return 1
+		case x < y:
+			return -1
+		default:
+			return 0
+		}
+	}, env)
+}
+
+func (e *Env) inferenceCounter() (*inferenceCounter, bool) {
+	t, ok := e.lookup(varInference)
+	if !ok {
+		return nil, false
+	}
+	c, ok := t.(*inferenceCounter)
+	return c, ok && c != nil
+}
+
+// limitInferences returns a new Env in which the predicate calls are limited to limit in addition to the current limits.
+func (e *Env) limitInferences(limit uint64) (*Env, *inferenceCounter) {
+	parent, _ := e.inferenceCounter()
+	c := inferenceCounter{limit: limit, parent: parent}
+	return e.bind(varInference, &c), &c
+}
+
+// infer counts a predicate call. It returns false if the call exceeds the limit of the counter or its ancestors.
+// Once the limit is exceeded, the calls after that fail too so that catching the error doesn't let the goal run on.
+func (c *inferenceCounter) infer() bool {
+	ok := true
+	for ; c != nil; c = c.parent {
+		c.count++
+		if c.count > c.limit {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (c *inferenceCounter) exceeded() bool {
+	return c.count > c.limit
+}
@@ -20,6 +20,7 @@ func TestInteger_WriteTerm(t *testing.T) {
 		{title: "positive", i: 33, output: `33`},
 		{title: "positive following unary minus", i: 33, opts: WriteOptions{left: operator{name: atomMinus, specifier: operatorSpecifierFX}}, output: ` (33)`},
 		{title: "negative", i: -33, output: `-33`},
+		{title: "zero following unary minus", i: 0, opts: WriteOptions{left: operator{name: atomMinus, specifier: operatorSpecifierFX}}, output: ` (0)`},
 		{title: "ambiguous 0b", i: 0, opts: WriteOptions{right: operator{name: NewAtom(`b0`)}}, output: `0 `},  // So that it won't be 0b0.
 		{title: "ambiguous 0o", i: 0, opts: WriteOptions{right: operator{name: NewAtom(`o0`)}}, output: `0 `},  // So that it won't be 0o0.
 		{title: "ambiguous 0x", i: 0, opts: WriteOptions{right: operator{name: NewAtom(`x0`)}}, output: `0 `},  // So that it won't be 0x0.
@@ -47,6 +48,9 @@ func TestInteger_Compare(t *testing.T) {
 	}{
 		{title: `1 > X`, i: 1, t: x, o: 1},
 		{title: `1 > 1.0`, i: 1, t: Float(1), o: 1},
+		{title: `1 > 0.5`, i: 1, t: Float(0.5), o: 1},
+		{title: `1 < 1.5`, i: 1, t: Float(1.5), o: -1},
+		{title: `9007199254740993 > 9007199254740992.0`, i: 9007199254740993, t: Float(9007199254740992), o: 1},
 		{title: `1 > 0`, i: 1, t: Integer(0), o: 1},
 		{title: `1 = 1`, i: 1, t: Integer(1), o: 0},
 		{title: `1 < 2`, i: 1, t: Integer(2), o: -1},
@@ -137,7 +137,7 @@ func (i *altIterator) Next() bool {
 		}
 
 		// if-then-else construct
-		if c, ok := i.Env.Resolve(a.Arg(0)).(Compound); ok && c.Functor() == atomThen && c.Arity() == 2 {
+		if c, ok := i.Env.Resolve(a.Arg(0)).(Compound); ok && (c.Functor() == atomThen || c.Functor() == atomSoftCut) && c.Arity() == 2 {
 			i.current = a
 			i.Alt = nil
 			return true
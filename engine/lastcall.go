@@ -0,0 +1,44 @@
+package engine
+
+// minCompaction is the number of the bindings to add before the first compaction in a chain of last calls.
+const minCompaction = 1024
+
+// lastCall is a chain of last calls which continue with the same continuation.
+// Since the continuation was made before the chain started, it doesn't refer to the variables newer than mark. So, once the
+// chain moves on to the next call, the bindings of the newer variables are garbage unless they're reachable from the
+// arguments of the call.
+type lastCall struct {
+	mark Variable
+
+	// next is the size of Env to compact at. It grows with the cost of the last compaction so that a chain of last calls
+	// spends amortized constant time per binding for the compactions.
+	next int
+}
+
+func newLastCall(env *Env) *lastCall {
+	// The compaction goes through the bindings at least.
+	n := env.len()
+	return &lastCall{
+		mark: lastVariable(),
+		next: compactionPoint(n, n),
+	}
+}
+
+// gc drops the bindings that nothing refers to anymore if env has grown enough since the last compaction.
+func (lc *lastCall) gc(env *Env, args []Term) (*Env, *lastCall) {
+	if env.len() <= lc.next {
+		return env, lc
+	}
+	env, work := env.compact(lc.mark, args)
+	return env, &lastCall{
+		mark: lc.mark,
+		next: compactionPoint(env.len(), work),
+	}
+}
+
+func compactionPoint(size, work int) int {
+	if work < minCompaction {
+		work = minCompaction
+	}
+	return size + work
+}
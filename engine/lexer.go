@@ -17,6 +17,24 @@ type Lexer struct {
 
 	buf    bytes.Buffer
 	offset int
+
+	// pos is the number of bytes read so far.
+	pos int
+
+	// digitSeparators enables underscores between digits e.g. 1_000_000.
+	digitSeparators bool
+
+	// keepComments enables collecting comments into comments.
+	keepComments bool
+	comments     []comment
+	commentBuf   strings.Builder
+	commentPos   int
+}
+
+// comment is a comment text and its starting position in bytes.
+type comment struct {
+	pos  int
+	text string
 }
 
 // Token returns the next token.
@@ -35,6 +53,9 @@ func (l *Lexer) next() (rune, error) {
 
 func (l *Lexer) rawNext() (rune, error) {
 	r, _, err := l.input.ReadRune()
+	if err == nil {
+		l.pos += utf8.RuneLen(r)
+	}
 	return r, err
 }
 
@@ -46,7 +67,38 @@ func (l *Lexer) conv(r rune) rune {
 }
 
 func (l *Lexer) backup() {
-	_ = l.input.UnreadRune()
+	l.pos -= utf8.RuneLen(l.input.unread())
+}
+
+// readAhead returns the rune that has been read from the input but not consumed by any tokens yet.
+func (l *Lexer) readAhead() (rune, bool) {
+	if l.input.empty() {
+		return 0, false
+	}
+	return l.input.buf[l.input.start], true
+}
+
+func (l *Lexer) commentStart(delim string) {
+	if !l.keepComments {
+		return
+	}
+	l.commentPos = l.pos - len(delim)
+	l.commentBuf.Reset()
+	_, _ = l.commentBuf.WriteString(delim)
+}
+
+func (l *Lexer) commentAccept(r rune) {
+	if !l.keepComments {
+		return
+	}
+	_, _ = l.commentBuf.WriteRune(r)
+}
+
+func (l *Lexer) commentEnd() {
+	if !l.keepComments {
+		return
+	}
+	l.comments = append(l.comments, comment{pos: l.commentPos, text: l.commentBuf.String()})
 }
 
 func (l *Lexer) accept(r rune) {
@@ -242,6 +294,7 @@ func (l *Lexer) layoutTextSequence(afterLayout bool) (Token, error) {
 			afterLayout = true
 			continue
 		case r == '%':
+			l.commentStart("%")
 			return l.commentText(false)
 		case r == '/':
 			return l.commentOpen()
@@ -259,16 +312,26 @@ func (l *Lexer) commentText(bracketed bool) (Token, error) {
 			case err != nil:
 				return Token{}, err
 			case r == '*':
+				l.commentAccept(r)
 				return l.commentClose()
+			default:
+				l.commentAccept(r)
 			}
 		}
 	} else {
 		for {
 			switch r, err := l.next(); {
+			case err == io.EOF:
+				// A single line comment may end at the end of input without a new line.
+				l.commentEnd()
+				return Token{}, err
 			case err != nil:
 				return Token{}, err
 			case r == '\n':
+				l.commentEnd()
 				return l.layoutTextSequence(true)
+			default:
+				l.commentAccept(r)
 			}
 		}
 	}
@@ -282,6 +345,7 @@ func (l *Lexer) commentOpen() (Token, error) {
 	case err != nil:
 		return Token{}, err
 	case r == '*':
+		l.commentStart("/*")
 		return l.commentText(true)
 	default:
 		l.backup()
@@ -295,8 +359,11 @@ func (l *Lexer) commentClose() (Token, error) {
 	case err != nil:
 		return Token{}, err
 	case r == '/':
+		l.commentAccept(r)
+		l.commentEnd()
 		return l.layoutTextSequence(true)
 	default:
+		l.commentAccept(r)
 		return l.commentText(true)
 	}
 }
@@ -607,6 +674,10 @@ func (l *Lexer) integerConstant() (Token, error) {
 			return Token{}, err
 		case isDecimalDigitChar(r):
 			l.accept(r)
+		case r == '_' && l.digitSeparators:
+			if t, ok, err := l.digitSeparator(); err != nil || !ok {
+				return t, err
+			}
 		case r == '.':
 			switch r, err := l.next(); {
 			case err == io.EOF:
@@ -701,6 +772,23 @@ func (l *Lexer) hexadecimalConstant() (Token, error) {
 	}
 }
 
+// digitSeparator skips an underscore between digits. It returns an invalid token unless a digit follows.
+func (l *Lexer) digitSeparator() (Token, bool, error) {
+	switch r, err := l.next(); {
+	case err == io.EOF:
+		break
+	case err != nil:
+		return Token{}, false, err
+	case isDecimalDigitChar(r):
+		l.accept(r)
+		return Token{}, true, nil
+	default:
+		l.backup()
+	}
+	l.accept('_')
+	return Token{kind: tokenInvalid, val: l.chunk()}, false, nil
+}
+
 //// Floating point numbers
 
 func (l *Lexer) fraction() (Token, error) {
@@ -712,6 +800,10 @@ func (l *Lexer) fraction() (Token, error) {
 			return Token{}, err
 		case isDecimalDigitChar(r):
 			l.accept(r)
+		case r == '_' && l.digitSeparators:
+			if t, ok, err := l.digitSeparator(); err != nil || !ok {
+				return t, err
+			}
 		case isExponentChar(r):
 			var sign rune
 			switch r, err := l.next(); {
@@ -934,6 +1026,12 @@ func (b *runeRingBuffer) empty() bool {
 	return b.start == b.end
 }
 
+// unread backs up by a rune and returns it.
+func (b *runeRingBuffer) unread() rune {
+	b.backup()
+	return b.buf[b.start]
+}
+
 func (b *runeRingBuffer) backup() {
 	b.start--
 	b.start %= len(b.buf)
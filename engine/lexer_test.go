@@ -13,6 +13,7 @@ func TestLexer_Token(t *testing.T) {
 	tests := []struct {
 		input           string
 		charConversions map[rune]rune
+		digitSeparators bool
 		token           Token
 		err             error
 	}{
@@ -22,6 +23,9 @@ func TestLexer_Token(t *testing.T) {
 		{input: ".", token: Token{kind: tokenEnd, val: "."}},
 		{input: ";", token: Token{kind: tokenSemicolon, val: ";"}},
 		{input: "!", token: Token{kind: tokenCut, val: "!"}},
+		{input: ";;", token: Token{kind: tokenSemicolon, val: ";"}},
+		{input: ";-", token: Token{kind: tokenSemicolon, val: ";"}},
+		{input: "!=", token: Token{kind: tokenCut, val: "!"}},
 		{input: "(", token: Token{kind: tokenOpenCT, val: "("}},
 		{input: " (", token: Token{kind: tokenOpen, val: "("}},
 		{input: ")", token: Token{kind: tokenClose, val: ")"}},
@@ -190,11 +194,21 @@ a quoted ident"`}},
 
 		{input: `abc`, charConversions: map[rune]rune{'b': 'a'}, token: Token{kind: tokenLetterDigit, val: "aac"}},
 		{input: `'abc'`, charConversions: map[rune]rune{'b': 'a'}, token: Token{kind: tokenQuoted, val: "'abc'"}},
+
+		{input: `1_000`, token: Token{kind: tokenInteger, val: "1"}},
+		{input: `1_000`, digitSeparators: true, token: Token{kind: tokenInteger, val: "1000"}},
+		{input: `1_000_000.0`, digitSeparators: true, token: Token{kind: tokenFloatNumber, val: "1000000.0"}},
+		{input: `1.000_5`, digitSeparators: true, token: Token{kind: tokenFloatNumber, val: "1.0005"}},
+		{input: `1__0`, digitSeparators: true, token: Token{kind: tokenInvalid, val: "1_"}},
+		{input: `1_`, digitSeparators: true, token: Token{kind: tokenInvalid, val: "1_"}},
+		{input: `1_a`, digitSeparators: true, token: Token{kind: tokenInvalid, val: "1_"}},
+		{input: `1_🙈`, digitSeparators: true, err: errMonkey},
+		{input: `_1`, digitSeparators: true, token: Token{kind: tokenVariable, val: "_1"}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			l := Lexer{input: newRuneRingBuffer(noMonkeyReader{strings.NewReader(tt.input)}), charConversions: tt.charConversions}
+			l := Lexer{input: newRuneRingBuffer(noMonkeyReader{strings.NewReader(tt.input)}), charConversions: tt.charConversions, digitSeparators: tt.digitSeparators}
 
 			token, err := l.Token()
 			assert.Equal(t, tt.token, token)
@@ -203,6 +217,33 @@ a quoted ident"`}},
 	}
 }
 
+func TestLexer_comments(t *testing.T) {
+	tests := []struct {
+		title        string
+		input        string
+		keepComments bool
+		comments     []comment
+	}{
+		{title: "not kept", input: "% foo\n/* bar */ a", keepComments: false},
+		{title: "kept", input: "% foo\n/* bar */ a", keepComments: true, comments: []comment{{pos: 0, text: "% foo"}, {pos: 6, text: "/* bar */"}}},
+		{title: "end of input", input: "a. % foo", keepComments: true, comments: []comment{{pos: 3, text: "% foo"}}},
+		{title: "multibyte", input: "ア/* bar */", keepComments: true, comments: []comment{{pos: 3, text: "/* bar */"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			l := Lexer{input: newRuneRingBuffer(strings.NewReader(tt.input)), keepComments: tt.keepComments}
+			for {
+				if _, err := l.Token(); err != nil {
+					assert.Equal(t, io.EOF, err)
+					break
+				}
+			}
+			assert.Equal(t, tt.comments, l.comments)
+		})
+	}
+}
+
 var errMonkey = errors.New("monkey")
 
 type noMonkeyReader struct {
@@ -11,7 +11,8 @@ var (
 )
 
 var constants = map[Atom]Number{
-	atomPi: Float(math.Pi),
+	atomPi:     Float(math.Pi),
+	atomSmallE: Float(math.E),
 }
 
 var unaryFunctors = map[Atom]func(Number) (Number, error){
@@ -57,6 +58,7 @@ var binaryFunctors = map[Atom]func(Number, Number) (Number, error){
 	atomCaret:             integerPower,
 	atomAtan2:             atan2,
 	atomXor:               xor,
+	atomGCD:               gcd,
 }
 
 // Number is a prolog number, either Integer or Float.
@@ -97,6 +99,10 @@ func eval(expression Term, env *Env) (_ Number, err error) {
 			}
 			return f(x)
 		case 2:
+			// A list of exactly one element evaluates to the element e.g. "a" where double_quotes is codes.
+			if t.Functor() == atomDot && env.Resolve(t.Arg(1)) == atomEmptyList {
+				return eval(t.Arg(0), env)
+			}
 			f, ok := binaryFunctors[t.Functor()]
 			if !ok {
 				return nil, typeError(validTypeEvaluable, atomSlash.Apply(t.Functor(), Integer(2)), env)
@@ -740,7 +746,7 @@ func bitwiseLeftShift(n, s Number) (Number, error) {
 	case Integer:
 		switch s := s.(type) {
 		case Integer:
-			return Integer(n << s), nil
+			return shiftLeftI(n, s)
 		default:
 			return nil, typeError(validTypeInteger, s, nil)
 		}
@@ -1047,6 +1053,30 @@ func xor(x, y Number) (Number, error) {
 	return vx ^ vy, nil
 }
 
+// gcd returns the greatest common divisor of x and y.
+func gcd(x, y Number) (Number, error) {
+	vx, ok := x.(Integer)
+	if !ok {
+		return nil, typeError(validTypeInteger, x, nil)
+	}
+
+	vy, ok := y.(Integer)
+	if !ok {
+		return nil, typeError(validTypeInteger, y, nil)
+	}
+
+	for vy != 0 {
+		vx, vy = vy, vx%vy
+	}
+	if vx == minInt {
+		return nil, exceptionalValueIntOverflow
+	}
+	if vx < 0 {
+		vx = -vx
+	}
+	return vx, nil
+}
+
 // Comparison
 
 func eqF(x, y Float) bool {
@@ -1195,6 +1225,26 @@ func ceilingFtoI(x Float) (Integer, error) {
 
 // Integer operations
 
+func shiftLeftI(n, s Integer) (Integer, error) {
+	if s < 0 {
+		if s < -63 {
+			s = -63
+		}
+		return n >> -s, nil
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if s > 63 {
+		return 0, exceptionalValueIntOverflow
+	}
+	r := n << s
+	if r>>s != n {
+		return 0, exceptionalValueIntOverflow
+	}
+	return r, nil
+}
+
 func addI(x, y Integer) (Integer, error) {
 	switch {
 	case y > 0 && x > maxInt-y:
@@ -1430,3 +1480,24 @@ func divIF(n Integer, x Float) (Float, error) {
 func divII(n, m Integer) (Float, error) {
 	return divF(Float(n), Float(m))
 }
+
+// compareIntegerFloat compares i and f by their values. If they're equal, f precedes i.
+func compareIntegerFloat(i Integer, f Float) int {
+	switch x := Float(i); {
+	case x < f:
+		return -1
+	case x > f:
+		return 1
+	}
+
+	// Float(i) might have lost precision.
+	if f >= math.MinInt64 && f < math.MaxInt64 {
+		switch j := Integer(f); {
+		case i < j:
+			return -1
+		case i > j:
+			return 1
+		}
+	}
+	return 1
+}
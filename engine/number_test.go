@@ -23,10 +23,29 @@ func TestIs(t *testing.T) {
 		{title: "float", result: Float(1), expression: Float(1), ok: true},
 
 		{title: "pi", result: Float(math.Pi), expression: atomPi, ok: true},
+		{title: "e", result: Float(math.E), expression: atomSmallE, ok: true},
+		{title: "foo", expression: foo, err: typeError(validTypeEvaluable, atomSlash.Apply(foo, Integer(0)), nil)},
+
+		{title: "gcd(12, 8)", result: Integer(4), expression: atomGCD.Apply(Integer(12), Integer(8)), ok: true},
+		{title: "gcd(12, 18)", result: Integer(6), expression: atomGCD.Apply(Integer(12), Integer(18)), ok: true},
+		{title: "gcd(-12, 18)", result: Integer(6), expression: atomGCD.Apply(Integer(-12), Integer(18)), ok: true},
+		{title: "gcd(0, -5)", result: Integer(5), expression: atomGCD.Apply(Integer(0), Integer(-5)), ok: true},
+		{title: "gcd(0, 0)", result: Integer(0), expression: atomGCD.Apply(Integer(0), Integer(0)), ok: true},
+		{title: "gcd(minInt, 0)", expression: atomGCD.Apply(Integer(math.MinInt64), Integer(0)), err: evaluationError(exceptionalValueIntOverflow, nil)},
+		{title: "gcd(1.0, 2)", expression: atomGCD.Apply(Float(1), Integer(2)), err: typeError(validTypeInteger, Float(1), nil)},
+		{title: "gcd(1, 2.0)", expression: atomGCD.Apply(Integer(1), Float(2)), err: typeError(validTypeInteger, Float(2), nil)},
+
+		{title: "4 / 2", result: Float(2), expression: atomSlash.Apply(Integer(4), Integer(2)), ok: true},
+		{title: "7 // 2", result: Integer(3), expression: atomSlashSlash.Apply(Integer(7), Integer(2)), ok: true},
+		{title: "1 / 0", expression: atomSlash.Apply(Integer(1), Integer(0)), err: evaluationError(exceptionalValueZeroDivisor, nil)},
 
 		{title: "1 + 1", result: Integer(2), expression: atomPlus.Apply(Integer(1), Integer(1)), ok: true},
 		{title: "maxInt + 1", expression: atomPlus.Apply(Integer(math.MaxInt64), Integer(1)), err: evaluationError(exceptionalValueIntOverflow, nil)},
 		{title: "minInt - 1", expression: atomPlus.Apply(Integer(math.MinInt64), Integer(-1)), err: evaluationError(exceptionalValueIntOverflow, nil)},
+		{title: "0'A + 1", result: Integer(66), expression: atomPlus.Apply(Integer('A'), Integer(1)), ok: true},
+		{title: `"a" + 1`, result: Integer(98), expression: atomPlus.Apply(List(Integer('a')), Integer(1)), ok: true},
+		{title: "[X]", expression: List(NewVariable()), err: InstantiationError(nil)},
+		{title: "[1, 2]", expression: List(Integer(1), Integer(2)), err: typeError(validTypeEvaluable, atomSlash.Apply(atomDot, Integer(2)), nil)},
 		{title: "1 + 1.0", result: Float(2), expression: atomPlus.Apply(Integer(1), Float(1)), ok: true},
 		{title: "1.0 + 1", result: Float(2), expression: atomPlus.Apply(Float(1), Integer(1)), ok: true},
 		{title: "1.0 + maxFloat", expression: atomPlus.Apply(Float(1), Float(math.MaxFloat64)), err: evaluationError(exceptionalValueFloatOverflow, nil)},
@@ -42,6 +61,9 @@ func TestIs(t *testing.T) {
 		{title: "mock - mock", expression: atomMinus.Apply(&mockNumber{}, &mockNumber{}), err: evaluationError(exceptionalValueUndefined, nil)},
 
 		{title: "1 * 1", result: Integer(1), expression: atomAsterisk.Apply(Integer(1), Integer(1)), ok: true},
+		// The bounded flag is always true. Overflow is an error instead of a big integer.
+		{title: "large * large", expression: atomAsterisk.Apply(Integer(3037000500), Integer(3037000500)), err: evaluationError(exceptionalValueIntOverflow, nil)},
+		{title: "large * large, no overflow", result: Integer(3037000499 * 3037000499), expression: atomAsterisk.Apply(Integer(3037000499), Integer(3037000499)), ok: true},
 		{title: "maxInt * 2", expression: atomAsterisk.Apply(Integer(math.MaxInt64), Integer(2)), err: evaluationError(exceptionalValueIntOverflow, nil)},
 		{title: "1 * 0", result: Integer(0), expression: atomAsterisk.Apply(Integer(1), Integer(0)), ok: true},
 		{title: "-1 * minInt", expression: atomAsterisk.Apply(Integer(-1), Integer(math.MinInt64)), err: evaluationError(exceptionalValueIntOverflow, nil)},
@@ -187,6 +209,7 @@ func TestIs(t *testing.T) {
 		{title: "sqrt(mock)", expression: atomSqrt.Apply(&mockNumber{}), err: evaluationError(exceptionalValueUndefined, nil)},
 		{title: "sqrt(-1.0)", result: Float(0), expression: atomSqrt.Apply(Float(-1)), err: evaluationError(exceptionalValueUndefined, nil)},
 
+		{title: "max(3, 5.0)", result: Float(5), expression: atomMax.Apply(Integer(3), Float(5)), ok: true},
 		{title: "max(1, 2)", result: Integer(2), expression: atomMax.Apply(Integer(1), Integer(2)), ok: true},
 		{title: "max(1, 1)", result: Integer(1), expression: atomMax.Apply(Integer(1), Integer(1)), ok: true},
 		{title: "max(1, 2.0)", result: Float(2), expression: atomMax.Apply(Integer(1), Float(2)), ok: true},
@@ -199,6 +222,7 @@ func TestIs(t *testing.T) {
 		{title: "max(1.0, mock)", expression: atomMax.Apply(Float(1), &mockNumber{}), err: evaluationError(exceptionalValueUndefined, nil)},
 		{title: "max(mock, 1)", expression: atomMax.Apply(&mockNumber{}, Integer(1)), err: evaluationError(exceptionalValueUndefined, nil)},
 
+		{title: "min(3, 5.0)", result: Integer(3), expression: atomMin.Apply(Integer(3), Float(5)), ok: true},
 		{title: "min(2, 1)", result: Integer(1), expression: atomMin.Apply(Integer(2), Integer(1)), ok: true},
 		{title: "min(1, 1)", result: Integer(1), expression: atomMin.Apply(Integer(1), Integer(1)), ok: true},
 		{title: "min(2, 1.0)", result: Float(1), expression: atomMin.Apply(Integer(2), Float(1)), ok: true},
@@ -260,6 +284,13 @@ func TestIs(t *testing.T) {
 		{title: "16.0 >> 2", expression: atomBitwiseRightShift.Apply(Float(16), Integer(2)), err: typeError(validTypeInteger, Float(16), nil)},
 
 		{title: "16 << 2", result: Integer(64), expression: atomBitwiseLeftShift.Apply(Integer(16), Integer(2)), ok: true},
+		{title: "16 << -2", result: Integer(4), expression: atomBitwiseLeftShift.Apply(Integer(16), Integer(-2)), ok: true},
+		{title: "0 << 100", result: Integer(0), expression: atomBitwiseLeftShift.Apply(Integer(0), Integer(100)), ok: true},
+		{title: "1 << 62", result: Integer(1 << 62), expression: atomBitwiseLeftShift.Apply(Integer(1), Integer(62)), ok: true},
+		{title: "-1 << 63", result: Integer(math.MinInt64), expression: atomBitwiseLeftShift.Apply(Integer(-1), Integer(63)), ok: true},
+		{title: "1 << 63", expression: atomBitwiseLeftShift.Apply(Integer(1), Integer(63)), err: evaluationError(exceptionalValueIntOverflow, nil)},
+		{title: "1 << 64", expression: atomBitwiseLeftShift.Apply(Integer(1), Integer(64)), err: evaluationError(exceptionalValueIntOverflow, nil)},
+		{title: "maxInt << 1", expression: atomBitwiseLeftShift.Apply(Integer(math.MaxInt64), Integer(1)), err: evaluationError(exceptionalValueIntOverflow, nil)},
 		{title: "16 << 2.0", expression: atomBitwiseLeftShift.Apply(Integer(16), Float(2)), err: typeError(validTypeInteger, Float(2), nil)},
 		{title: "16.0 << 2", expression: atomBitwiseLeftShift.Apply(Float(16), Integer(2)), err: typeError(validTypeInteger, Float(16), nil)},
 
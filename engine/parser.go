@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -44,17 +45,24 @@ func NewParser(vm *VM, r io.RuneReader) *Parser {
 	if vm.operators == nil {
 		vm.operators = operators{}
 	}
-	return &Parser{
+	p := Parser{
 		lexer: Lexer{
 			input: newRuneRingBuffer(r),
 		},
 		operators:    vm.operators,
 		doubleQuotes: vm.doubleQuotes,
 	}
+	p.lexer.digitSeparators = vm.digitSeparators
+	if vm.charConvEnabled {
+		p.lexer.charConversions = vm.charConversions
+	}
+	return &p
 }
 
 // SetPlaceholder registers placeholder and its arguments. Every occurrence of placeholder will be replaced by arguments.
 // Mismatch of the number of occurrences of placeholder and the number of arguments raises an error.
+// Maps are converted to lists of Key-Value pairs and named structs to compounds with the type name as the functor and
+// the exported fields as the arguments. Self-referential values can't be converted.
 func (p *Parser) SetPlaceholder(placeholder Atom, args ...interface{}) error {
 	p.placeholder = placeholder
 	p.args = make([]Term, len(args))
@@ -69,6 +77,31 @@ func (p *Parser) SetPlaceholder(placeholder Atom, args ...interface{}) error {
 }
 
 func termOf(o reflect.Value) (Term, error) {
+	return termOfValue(o, map[reference]struct{}{})
+}
+
+// reference identifies a pointer, map, or slice value.
+type reference struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// termOfValue converts o to a term. ancestors are the references which o is reached through so that it fails on
+// self-referential values instead of recursing infinitely.
+func termOfValue(o reflect.Value, ancestors map[reference]struct{}) (Term, error) {
+	switch o.Kind() {
+	case reflect.Map, reflect.Pointer, reflect.Slice:
+		if o.IsNil() {
+			break
+		}
+		r := reference{ptr: o.Pointer(), typ: o.Type()}
+		if _, ok := ancestors[r]; ok {
+			return nil, fmt.Errorf("can't convert a self-referential value to term: %v", o.Type())
+		}
+		ancestors[r] = struct{}{}
+		defer delete(ancestors, r)
+	}
+
 	switch o.Kind() {
 	case reflect.Float32, reflect.Float64:
 		return Float(o.Float()), nil
@@ -81,12 +114,56 @@ func termOf(o reflect.Value) (Term, error) {
 		es := make([]Term, l)
 		for i := 0; i < l; i++ {
 			var err error
-			es[i], err = termOf(o.Index(i))
+			es[i], err = termOfValue(o.Index(i), ancestors)
 			if err != nil {
 				return nil, err
 			}
 		}
 		return List(es...), nil
+	case reflect.Map:
+		keys := o.MapKeys()
+		ps := make([]Term, len(keys))
+		for i, k := range keys {
+			key, err := termOfValue(k, ancestors)
+			if err != nil {
+				return nil, err
+			}
+			value, err := termOfValue(o.MapIndex(k), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			ps[i] = pair(key, value)
+		}
+		sort.Slice(ps, func(i, j int) bool {
+			return ps[i].Compare(ps[j], nil) == -1
+		})
+		return List(ps...), nil
+	case reflect.Struct:
+		name := o.Type().Name()
+		if name == "" {
+			return nil, fmt.Errorf("can't convert to term: %v", o)
+		}
+		var args []Term
+		for i := 0; i < o.NumField(); i++ {
+			// Unexported fields are implementation details.
+			if !o.Type().Field(i).IsExported() {
+				continue
+			}
+			arg, err := termOfValue(o.Field(i), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		if len(args) == 0 {
+			return NewAtom(name), nil
+		}
+		return NewAtom(name).Apply(args...), nil
+	case reflect.Interface, reflect.Pointer:
+		if o.IsNil() {
+			return nil, fmt.Errorf("can't convert to term: %v", o)
+		}
+		return termOfValue(o.Elem(), ancestors)
 	default:
 		return nil, fmt.Errorf("can't convert to term: %v", o)
 	}
@@ -112,6 +189,9 @@ func (p *Parser) current() Token {
 }
 
 // Term parses a term followed by a full stop.
+// It returns io.EOF if the input ends before the full stop. In that case, the parser can't resume and the term has to be
+// parsed again from the beginning by a new parser with more input appended.
+// VM.Compile reports it as io.ErrUnexpectedEOF since the text won't get any more input.
 func (p *Parser) Term() (Term, error) {
 	t, err := p.term(1201)
 	switch err {
@@ -123,8 +203,10 @@ func (p *Parser) Term() (Term, error) {
 		return nil, err
 	}
 
-	switch t, _ := p.next(); t.kind {
-	case tokenEnd:
+	switch t, err := p.next(); {
+	case err == io.EOF:
+		return nil, err
+	case t.kind == tokenEnd:
 		break
 	default:
 		p.backup()
@@ -10,7 +10,9 @@ import (
 
 func TestParser_Term(t *testing.T) {
 	ops := operators{}
+	ops.define(1100, operatorSpecifierXFY, NewAtom(`;`))
 	ops.define(1000, operatorSpecifierXFY, NewAtom(`,`))
+	ops.define(700, operatorSpecifierXFX, NewAtom(`=`))
 	ops.define(500, operatorSpecifierYFX, NewAtom(`+`))
 	ops.define(400, operatorSpecifierYFX, NewAtom(`*`))
 	ops.define(200, operatorSpecifierFY, NewAtom(`-`))
@@ -26,6 +28,11 @@ func TestParser_Term(t *testing.T) {
 	}{
 		{input: ``, err: io.EOF},
 		{input: `foo`, err: io.EOF},
+		{input: `'foo`, err: io.EOF},
+		{input: `foo('bar`, err: io.EOF},
+		{input: `"foo`, err: io.EOF},
+		{input: `/* foo`, err: io.EOF},
+		{input: `foo /* bar`, err: io.EOF},
 		{input: `.`, err: unexpectedTokenError{actual: Token{kind: tokenEnd, val: "."}}},
 
 		{input: `(foo).`, term: NewAtom("foo")},
@@ -84,6 +91,28 @@ func TestParser_Term(t *testing.T) {
 			}
 		}},
 
+		{input: `!.`, term: atomCut},
+		{input: `;.`, term: atomSemiColon},
+		{input: `X = !.`, termLazy: func() Term {
+			return atomEqual.Apply(lastVariable(), atomCut)
+		}, vars: func() []ParsedVariable {
+			return []ParsedVariable{
+				{Name: NewAtom("X"), Variable: lastVariable(), Count: 1},
+			}
+		}},
+		{input: `X = ;.`, err: unexpectedTokenError{actual: Token{kind: tokenSemicolon, val: ";"}}, vars: func() []ParsedVariable {
+			return []ParsedVariable{
+				{Name: NewAtom("X"), Variable: lastVariable(), Count: 1},
+			}
+		}},
+		{input: `X = (;).`, termLazy: func() Term {
+			return atomEqual.Apply(lastVariable(), atomSemiColon)
+		}, vars: func() []ParsedVariable {
+			return []ParsedVariable{
+				{Name: NewAtom("X"), Variable: lastVariable(), Count: 1},
+			}
+		}},
+
 		{input: `foo(a, b).`, term: &compound{functor: NewAtom("foo"), args: []Term{NewAtom("a"), NewAtom("b")}}},
 		{input: `foo(-(a)).`, term: &compound{functor: NewAtom("foo"), args: []Term{&compound{functor: atomMinus, args: []Term{NewAtom("a")}}}}},
 		{input: `foo(-).`, term: &compound{functor: NewAtom("foo"), args: []Term{atomMinus}}},
@@ -91,6 +120,9 @@ func TestParser_Term(t *testing.T) {
 		{input: `foo([]).`, term: &compound{functor: NewAtom("foo"), args: []Term{atomEmptyList}}},
 		{input: `foo(a, ()).`, err: unexpectedTokenError{actual: Token{kind: tokenClose, val: ")"}}},
 		{input: `foo(a b).`, err: unexpectedTokenError{actual: Token{kind: tokenLetterDigit, val: "b"}}},
+		{input: `foo((a, b)).`, term: &compound{functor: NewAtom("foo"), args: []Term{&compound{functor: atomComma, args: []Term{NewAtom("a"), NewAtom("b")}}}}},
+		{input: `foo(a ; b).`, err: unexpectedTokenError{actual: Token{kind: tokenSemicolon, val: ";"}}},
+		{input: `foo((a ; b)).`, term: &compound{functor: NewAtom("foo"), args: []Term{&compound{functor: atomSemiColon, args: []Term{NewAtom("a"), NewAtom("b")}}}}},
 		{input: `foo(a, b`, err: io.EOF},
 
 		{input: `[a, b].`, term: List(NewAtom("a"), NewAtom("b"))},
@@ -121,6 +153,11 @@ func TestParser_Term(t *testing.T) {
 
 		{input: `-a.`, term: &compound{functor: atomMinus, args: []Term{NewAtom("a")}}},
 		{input: `- .`, term: atomMinus},
+		{input: `- a.`, term: &compound{functor: atomMinus, args: []Term{NewAtom("a")}}},
+		{input: `-(a).`, term: &compound{functor: atomMinus, args: []Term{NewAtom("a")}}},
+		{input: `- (a).`, term: &compound{functor: atomMinus, args: []Term{NewAtom("a")}}},
+		{input: `-(a, b).`, term: &compound{functor: atomMinus, args: []Term{NewAtom("a"), NewAtom("b")}}},
+		{input: `- (a, b).`, term: &compound{functor: atomMinus, args: []Term{&compound{functor: atomComma, args: []Term{NewAtom("a"), NewAtom("b")}}}}},
 
 		{input: `a-- .`, term: &compound{functor: NewAtom(`--`), args: []Term{NewAtom(`a`)}}},
 
@@ -154,6 +191,11 @@ func TestParser_Term(t *testing.T) {
 		// https://github.com/ichiban/prolog/issues/219#issuecomment-1200489336
 		{input: `write('[]').`, term: &compound{functor: NewAtom(`write`), args: []Term{NewAtom(`[]`)}}},
 		{input: `write('{}').`, term: &compound{functor: NewAtom(`write`), args: []Term{NewAtom(`{}`)}}},
+
+		// Insufficient input
+		{input: `foo(a`, err: io.EOF},
+		{input: `foo(a, b)`, err: io.EOF},
+		{input: `'abc`, err: io.EOF},
 	}
 
 	for _, tc := range tests {
@@ -195,6 +237,100 @@ func TestParser_Replace(t *testing.T) {
 		assert.Equal(t, List(Float(1.0), Integer(2), NewAtom("foo"), List(NewAtom("a"), NewAtom("b"), NewAtom("c"))), list)
 	})
 
+	t.Run("struct", func(t *testing.T) {
+		type point struct {
+			X, Y int
+		}
+		type empty struct{}
+		type line struct {
+			From, To *point
+			Tags     []string
+		}
+
+		p := Parser{
+			lexer: Lexer{
+				input: newRuneRingBuffer(strings.NewReader(`[?, ?].`)),
+			},
+		}
+		assert.NoError(t, p.SetPlaceholder(NewAtom("?"), line{From: &point{X: 1, Y: 2}, To: &point{X: 3, Y: 4}, Tags: []string{"a"}}, empty{}))
+
+		list, err := p.Term()
+		assert.NoError(t, err)
+		assert.Equal(t, List(
+			NewAtom("line").Apply(
+				NewAtom("point").Apply(Integer(1), Integer(2)),
+				NewAtom("point").Apply(Integer(3), Integer(4)),
+				List(NewAtom("a")),
+			),
+			NewAtom("empty"),
+		), list)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		p := Parser{
+			lexer: Lexer{
+				input: newRuneRingBuffer(strings.NewReader(`foo(?).`)),
+			},
+		}
+		assert.NoError(t, p.SetPlaceholder(NewAtom("?"), map[string]interface{}{"b": 2, "a": "x", "c": []int{3}}))
+
+		foo, err := p.Term()
+		assert.NoError(t, err)
+		assert.Equal(t, NewAtom("foo").Apply(List(
+			pair(NewAtom("a"), NewAtom("x")),
+			pair(NewAtom("b"), Integer(2)),
+			pair(NewAtom("c"), List(Integer(3))),
+		)), foo)
+	})
+
+	t.Run("unexported fields", func(t *testing.T) {
+		type user struct {
+			Name     string
+			password string
+		}
+		type secret struct {
+			value string
+		}
+
+		p := Parser{
+			lexer: Lexer{
+				input: newRuneRingBuffer(strings.NewReader(`[?, ?].`)),
+			},
+		}
+		assert.NoError(t, p.SetPlaceholder(NewAtom("?"), user{Name: "alice", password: "x"}, secret{value: "y"}))
+
+		list, err := p.Term()
+		assert.NoError(t, err)
+		assert.Equal(t, List(NewAtom("user").Apply(NewAtom("alice")), NewAtom("secret")), list)
+	})
+
+	t.Run("self-referential", func(t *testing.T) {
+		type node struct {
+			Value int
+			Next  *node
+		}
+		n := node{Value: 1}
+		n.Next = &n
+
+		p := Parser{
+			lexer: Lexer{
+				input: newRuneRingBuffer(strings.NewReader(`foo(?).`)),
+			},
+		}
+		assert.Error(t, p.SetPlaceholder(NewAtom("?"), &n))
+
+		m := map[string]interface{}{}
+		m["self"] = m
+		assert.Error(t, p.SetPlaceholder(NewAtom("?"), m))
+
+		// Shared values are not self-referential.
+		type leaf struct {
+			Value int
+		}
+		shared := &leaf{Value: 2}
+		assert.NoError(t, p.SetPlaceholder(NewAtom("?"), []*leaf{shared, shared}))
+	})
+
 	t.Run("invalid argument", func(t *testing.T) {
 		p := Parser{
 			lexer: Lexer{
@@ -282,6 +418,38 @@ func TestParser_Number(t *testing.T) {
 	}
 }
 
+func TestParser_Term_insufficient(t *testing.T) {
+	ops := operators{}
+	ops.define(1000, operatorSpecifierXFY, atomComma)
+	ops.define(1200, operatorSpecifierXFX, atomIf)
+
+	chunks := []string{"foo(X, Y) :-\n  bar(X,", " Y).\n"}
+
+	var input strings.Builder
+	_, _ = input.WriteString(chunks[0])
+	p := Parser{
+		lexer: Lexer{
+			input: newRuneRingBuffer(strings.NewReader(input.String())),
+		},
+		operators: ops,
+	}
+	_, err := p.Term()
+	assert.Equal(t, io.EOF, err)
+
+	// The parser doesn't resume. Parse again from the beginning with the accumulated input.
+	_, _ = input.WriteString(chunks[1])
+	p = Parser{
+		lexer: Lexer{
+			input: newRuneRingBuffer(strings.NewReader(input.String())),
+		},
+		operators: ops,
+	}
+	term, err := p.Term()
+	assert.NoError(t, err)
+	x, y := p.Vars[0].Variable, p.Vars[1].Variable
+	assert.Equal(t, atomIf.Apply(NewAtom("foo").Apply(x, y), NewAtom("bar").Apply(x, y)), term)
+}
+
 func TestParser_More(t *testing.T) {
 	p := Parser{
 		lexer: Lexer{
@@ -297,3 +465,27 @@ func TestParser_More(t *testing.T) {
 	assert.Equal(t, NewAtom("bar"), term)
 	assert.False(t, p.More())
 }
+
+func TestParser_Term_trailingComment(t *testing.T) {
+	for _, input := range []string{
+		"foo. % trailing comment\nbar.",
+		"foo.% trailing comment\nbar.",
+		"foo. /* trailing comment */ bar.",
+	} {
+		t.Run(input, func(t *testing.T) {
+			p := Parser{
+				lexer: Lexer{
+					input: newRuneRingBuffer(strings.NewReader(input)),
+				},
+			}
+			term, err := p.Term()
+			assert.NoError(t, err)
+			assert.Equal(t, NewAtom("foo"), term)
+			assert.True(t, p.More())
+			term, err = p.Term()
+			assert.NoError(t, err)
+			assert.Equal(t, NewAtom("bar"), term)
+			assert.False(t, p.More())
+		})
+	}
+}
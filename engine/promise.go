@@ -22,6 +22,9 @@ type Promise struct {
 	cutParent *Promise
 	repeat    bool
 	recover   func(error) *Promise
+
+	// 1 + the index in the promise stack. 0 if it hasn't been forced yet.
+	frame int
 }
 
 // Delay delays an execution of k.
@@ -44,6 +47,22 @@ func Error(err error) *Promise {
 
 var dummyCutParent Promise
 
+// Cut returns a promise that once the execution reaches it, it eliminates the alternatives created since parent.
+// Since Call is a cut barrier by itself, a custom control construct like once/1 can be written as:
+//
+//	var p *Promise
+//	p = Call(vm, goal, func(env *Env) *Promise {
+//		return Cut(p, func(context.Context) *Promise {
+//			return k(env)
+//		})
+//	}, env)
+//	return p
+//
+// The cut eliminates the remaining choices of goal but keeps the ones outside of p intact.
+func Cut(parent *Promise, k func(context.Context) *Promise) *Promise {
+	return cut(parent, k)
+}
+
 // cut returns a promise that once the execution reaches it, it eliminates other possible choices.
 func cut(parent *Promise, k func(context.Context) *Promise) *Promise {
 	if parent == nil {
@@ -76,6 +95,7 @@ func catch(recover func(error) *Promise, k func(context.Context) *Promise) *Prom
 // Force enforces the delayed execution and returns the result. (i.e. trampoline)
 func (p *Promise) Force(ctx context.Context) (bool, error) {
 	stack := promiseStack{p}
+	ctx = context.WithValue(ctx, promiseStackKey{}, &stack)
 	for len(stack) > 0 {
 		select {
 		case <-ctx.Done():
@@ -102,9 +122,19 @@ func (p *Promise) Force(ctx context.Context) (bool, error) {
 				stack.popUntil(p.cutParent)
 				p.cutParent = nil // we don't have to do this again when we revisit.
 			}
+			p.frame = len(stack) + 1
 
 			// Try the child promises from left to right.
 			q := p.child(ctx)
+
+			// If it was the last choice, the child takes over the frame so that deterministic tail calls run in constant space.
+			if len(p.delayed) == 0 && p.recover == nil {
+				if len(q.delayed) > 0 {
+					q.frame = p.frame
+				}
+				stack = append(stack, q)
+				continue
+			}
 			stack = append(stack, p, q)
 		}
 	}
@@ -121,6 +151,24 @@ func (p *Promise) child(ctx context.Context) *Promise {
 
 type promiseStack []*Promise
 
+type promiseStackKey struct{}
+
+// hasChoicesSince reports whether there are alternatives left that were created since p.
+// ctx has to be the one given to a delayed execution by Force.
+func hasChoicesSince(ctx context.Context, p *Promise) bool {
+	s, ok := ctx.Value(promiseStackKey{}).(*promiseStack)
+	if !ok || p.frame == 0 || p.frame > len(*s) {
+		return true
+	}
+	// Everything above p's frame originates from p. The ones without delayed executions are not choice points but the ones with recovering functions.
+	for _, q := range (*s)[p.frame:] {
+		if len(q.delayed) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *promiseStack) pop() *Promise {
 	var p *Promise
 	p, *s, (*s)[len(*s)-1] = (*s)[len(*s)-1], (*s)[:len(*s)-1], nil
@@ -128,6 +176,13 @@ func (s *promiseStack) pop() *Promise {
 }
 
 func (s *promiseStack) popUntil(p *Promise) {
+	// p might have been taken over by its last child. In that case, everything above its frame originates from p.
+	if p.frame > 0 {
+		for len(*s) >= p.frame {
+			_ = s.pop()
+		}
+		return
+	}
 	for len(*s) > 0 {
 		if pop := s.pop(); pop == p {
 			break
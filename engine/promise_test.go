@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -73,3 +74,57 @@ func TestPromise_Force(t *testing.T) {
 		assert.Equal(t, 10, count)
 	})
 }
+
+func TestCut(t *testing.T) {
+	vm := VM{operators: operators{}}
+	vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+	vm.Register1(NewAtom("my_once"), func(vm *VM, goal Term, k Cont, env *Env) *Promise {
+		var p *Promise
+		p = Call(vm, goal, func(env *Env) *Promise {
+			return Cut(p, func(context.Context) *Promise {
+				return k(env)
+			})
+		}, env)
+		return p
+	})
+	assert.NoError(t, vm.Compile(context.Background(), `
+foo(a).
+foo(b).
+bar(X) :- my_once(foo(X)).
+bar(c).
+`))
+
+	var res []Term
+	x := NewVariable()
+	ok, err := Call(&vm, NewAtom("bar").Apply(x), func(env *Env) *Promise {
+		res = append(res, env.Resolve(x))
+		return Bool(false)
+	}, nil).Force(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []Term{NewAtom("a"), NewAtom("c")}, res)
+}
+
+func TestPromise_Force_lastCall(t *testing.T) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	// Without the last call optimization, the promise stack would keep all the exhausted promises.
+	var countDown func(n int) *Promise
+	countDown = func(n int) *Promise {
+		return Delay(func(context.Context) *Promise {
+			if n == 0 {
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				return Bool(true)
+			}
+			return countDown(n - 1)
+		})
+	}
+
+	ok, err := countDown(1_000_000).Force(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Less(t, int64(after.HeapAlloc)-int64(before.HeapAlloc), int64(10*1024*1024))
+}
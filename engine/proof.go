@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// ProofNode is a node of a proof tree. It represents a goal proved by a clause of a user-defined predicate.
+type ProofNode struct {
+	Goal     Term
+	Clause   Term
+	Children []*ProofNode
+}
+
+var varProof = NewVariable()
+
+// proofFrame is an immutable snapshot of a proof tree under construction.
+// It's kept in Env so that it rewinds on backtracking.
+type proofFrame struct {
+	goal, clause Term
+	children     []*proofFrame
+	parent       *proofFrame
+}
+
+// WriteTerm outputs the proofFrame to an io.Writer.
+func (f *proofFrame) WriteTerm(w io.Writer, _ *WriteOptions, _ *Env) error {
+	_, err := fmt.Fprintf(w, "<proof>(%p)", f)
+	return err
+}
+
+// Compare compares the proofFrame with a Term.
+func (f *proofFrame) Compare(t Term, env *Env) int {
+	return CompareAtomic[*proofFrame](f, t, func(f *proofFrame, g *proofFrame) int {
+		switch x, y := uintptr(unsafe.Pointer(f)), uintptr(unsafe.Pointer(g)); {
+		case x > y:
+			return 1
+		case x < y:
+			return -1
+		default:
+			return 0
+		}
+	}, env)
+}
+
+// CaptureProof returns a new Env in which the VM records the proof tree of goal.
+func (e *Env) CaptureProof(goal Term) *Env {
+	return e.bind(varProof, &proofFrame{goal: goal})
+}
+
+// ProofTree returns the proof tree recorded in the Env. It returns nil if the Env doesn't record a proof tree.
+func (e *Env) ProofTree() *ProofNode {
+	f, ok := e.proofFrame()
+	if !ok {
+		return nil
+	}
+	for f.parent != nil {
+		f = f.parent
+	}
+	return f.node(e)
+}
+
+func (e *Env) proofFrame() (*proofFrame, bool) {
+	t, ok := e.lookup(varProof)
+	if !ok {
+		return nil, false
+	}
+	f, ok := t.(*proofFrame)
+	return f, ok
+}
+
+func (f *proofFrame) node(env *Env) *ProofNode {
+	n := ProofNode{
+		Goal:   env.simplify(f.goal),
+		Clause: f.clause,
+	}
+	for _, c := range f.children {
+		n.Children = append(n.Children, c.node(env))
+	}
+	return &n
+}
+
+// enterProof starts a proof of goal as a child of the current one.
+func enterProof(goal Term, env *Env) *Env {
+	f, ok := env.proofFrame()
+	if !ok {
+		return env
+	}
+	return env.bind(varProof, &proofFrame{goal: goal, parent: f})
+}
+
+// proveBy records the clause that proves the current goal.
+func proveBy(clause Term, env *Env) *Env {
+	f, ok := env.proofFrame()
+	if !ok {
+		return env
+	}
+	g := *f
+	g.clause = clause
+	return env.bind(varProof, &g)
+}
+
+// exitProof completes the current proof and adds it to the parent.
+func exitProof(env *Env) *Env {
+	f, ok := env.proofFrame()
+	if !ok || f.parent == nil {
+		return env
+	}
+	p := *f.parent
+	p.children = append(p.children[:len(p.children):len(p.children)], f)
+	return env.bind(varProof, &p)
+}
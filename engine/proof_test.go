@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnv_ProofTree(t *testing.T) {
+	t.Run("not captured", func(t *testing.T) {
+		env := enterProof(NewAtom("foo"), nil)
+		env = proveBy(NewAtom("foo"), env)
+		env = exitProof(env)
+		assert.Nil(t, env.ProofTree())
+	})
+
+	t.Run("captured", func(t *testing.T) {
+		x := NewVariable()
+		root := NewEnv().CaptureProof(NewAtom("foo").Apply(x))
+
+		env := enterProof(NewAtom("foo").Apply(x), root)
+		env = proveBy(NewAtom("bar"), env)
+		env = enterProof(NewAtom("baz").Apply(x), env)
+		env = proveBy(NewAtom("baz").Apply(NewAtom("a")), env)
+		env, _ = env.Unify(x, NewAtom("a"))
+		env = exitProof(env)
+		env = exitProof(env)
+
+		assert.Equal(t, &ProofNode{
+			Goal: NewAtom("foo").Apply(NewAtom("a")),
+			Children: []*ProofNode{
+				{
+					Goal:   NewAtom("foo").Apply(NewAtom("a")),
+					Clause: NewAtom("bar"),
+					Children: []*ProofNode{
+						{Goal: NewAtom("baz").Apply(NewAtom("a")), Clause: NewAtom("baz").Apply(NewAtom("a"))},
+					},
+				},
+			},
+		}, env.ProofTree())
+
+		// The root Env is intact.
+		assert.Equal(t, &ProofNode{Goal: NewAtom("foo").Apply(x)}, root.ProofTree())
+	})
+}
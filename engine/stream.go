@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"unsafe"
 )
 
@@ -28,6 +29,7 @@ type Stream struct {
 
 	mode        ioMode
 	alias       Atom
+	mu          sync.Mutex // guards the read/write state while queries use the stream concurrently.
 	position    int64
 	endOfStream endOfStream
 	eofAction   eofAction
@@ -119,6 +121,9 @@ func (s *Stream) Name() string {
 // ReadByte reads a byte from the underlying source.
 // It throws an error if the stream is not an input binary stream.
 func (s *Stream) ReadByte() (byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.initRead(); err != nil {
 		return 0, err
 	}
@@ -146,6 +151,9 @@ func (s *Stream) ReadByte() (byte, error) {
 }
 
 func (s *Stream) UnreadByte() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.initRead(); err != nil {
 		return err
 	}
@@ -165,6 +173,9 @@ func (s *Stream) UnreadByte() error {
 // ReadRune reads the next rune from the underlying source.
 // It throws an error if the stream is not an input text stream.
 func (s *Stream) ReadRune() (r rune, size int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.initRead(); err != nil {
 		return 0, 0, err
 	}
@@ -191,6 +202,9 @@ func (s *Stream) ReadRune() (r rune, size int, err error) {
 }
 
 func (s *Stream) UnreadRune() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if err := s.initRead(); err != nil {
 		return err
 	}
@@ -210,6 +224,12 @@ func (s *Stream) UnreadRune() error {
 
 // Seek sets the offset to the underlying source/sink.
 func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seek(offset, whence)
+}
+
+func (s *Stream) seek(offset int64, whence int) (int64, error) {
 	if !s.reposition {
 		return 0, errReposition
 	}
@@ -319,7 +339,7 @@ func (s *Stream) initRead() error {
 		case eofActionError:
 			return errPastEndOfStream
 		case eofActionReset:
-			_, err := s.Seek(0, io.SeekStart)
+			_, err := s.seek(0, io.SeekStart)
 			return err
 		}
 	}
@@ -359,9 +379,13 @@ func (s *Stream) properties() []Term {
 		ps = append(ps, atomAlias.Apply(s.alias))
 	}
 
+	s.mu.Lock()
+	pos, eos := s.position, s.endOfStream
+	s.mu.Unlock()
+
 	ps = append(ps,
-		atomPosition.Apply(Integer(s.position)),
-		atomEndOfStream.Apply(s.endOfStream.Term()),
+		atomPosition.Apply(Integer(pos)),
+		atomEndOfStream.Apply(eos.Term()),
 		atomEOFAction.Apply(s.eofAction.Term()),
 	)
 
@@ -408,6 +432,8 @@ type textWriter struct {
 // It throws an error if the stream is not an output text stream.
 func (t textWriter) Write(p []byte) (int, error) {
 	s := t.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	n, err := s.sink.Write(p)
 	s.position += int64(n)
 	return n, err
@@ -421,6 +447,8 @@ type binaryWriter struct {
 // It throws an error if the stream is not an output binary stream.
 func (b binaryWriter) Write(p []byte) (int, error) {
 	s := b.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	n, err := s.sink.Write(p)
 	s.position += int64(n)
@@ -501,11 +529,15 @@ func (e endOfStream) Term() Term {
 }
 
 type streams struct {
+	mu      sync.RWMutex
 	elems   []*Stream
 	aliases map[Atom]*Stream
 }
 
 func (ss *streams) add(s *Stream) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
 	if s.alias != 0 {
 		if ss.aliases == nil {
 			ss.aliases = map[Atom]*Stream{}
@@ -517,6 +549,9 @@ func (ss *streams) add(s *Stream) {
 }
 
 func (ss *streams) remove(s *Stream) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
 	delete(ss.aliases, s.alias)
 	for i, e := range ss.elems {
 		if e == s {
@@ -532,6 +567,18 @@ func (ss *streams) remove(s *Stream) {
 }
 
 func (ss *streams) lookup(a Atom) (*Stream, bool) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
 	s, ok := ss.aliases[a]
 	return s, ok
 }
+
+func (ss *streams) all() []*Stream {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	ret := make([]*Stream, len(ss.elems))
+	copy(ret, ss.elems)
+	return ret
+}
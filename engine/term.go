@@ -18,12 +18,18 @@ type WriteOptions struct {
 	quoted        bool
 	variableNames map[Variable]Atom
 	numberVars    bool
+	fullStop      bool
+	maxDepth      Integer
 
 	ops         operators
 	priority    Integer
 	visited     map[termID]struct{}
 	prefixMinus bool
 	left, right operator
+	depth       Integer
+
+	// vm provides the names of the variables created by VM.NewNamedVariable.
+	vm *VM
 }
 
 func (o WriteOptions) withQuoted(quoted bool) *WriteOptions {
@@ -45,6 +51,11 @@ func (o WriteOptions) withPriority(priority Integer) *WriteOptions {
 	return &o
 }
 
+func (o WriteOptions) withDepth(depth Integer) *WriteOptions {
+	o.depth = depth
+	return &o
+}
+
 func (o WriteOptions) withLeft(op operator) *WriteOptions {
 	o.left = op
 	return &o
@@ -69,7 +80,7 @@ var defaultWriteOptions = WriteOptions{
 }
 
 // CompareAtomic compares a custom atomic term of type T with a Term and returns -1, 0, or 1.
-// The order is Variable < Float < Integer < Atom < custom atomic terms < Compound
+// The order is Variable < Number < Atom < custom atomic terms < Compound
 // where different types of custom atomic terms are ordered by the Go-syntax representation of the types.
 // It compares values of the same custom atomic term type T by the provided comparison function.
 func CompareAtomic[T Term](a T, t Term, cmp func(T, T) int, env *Env) int {
@@ -93,6 +104,13 @@ type termIDer interface {
 // termID is an identifier for a Term.
 type termID interface{}
 
+// ID returns a comparable identifier of the Term.
+// The same term always has the same identifier while distinct compounds have distinct identifiers even if they look alike.
+// Lists and partial lists are identified by their underlying storage.
+func ID(t Term) interface{} {
+	return id(t)
+}
+
 // id returns a termID for the Term.
 func id(t Term) termID {
 	switch t := t.(type) {
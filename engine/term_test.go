@@ -61,3 +61,22 @@ func TestCompareAtomic(t *testing.T) {
 		assert.Equal(t, tt.o, CompareAtomic[*y](tt.a, tt.t, tt.cmp, nil))
 	}
 }
+
+func TestID(t *testing.T) {
+	f := NewAtom("f").Apply(NewAtom("a"))
+	g := NewAtom("f").Apply(NewAtom("a"))
+	assert.Equal(t, ID(f), ID(f))
+	assert.True(t, ID(f) != ID(g))
+
+	l := List(NewAtom("a"), NewAtom("b"))
+	assert.Equal(t, ID(l), ID(l))
+	assert.True(t, ID(l) != ID(List(NewAtom("a"), NewAtom("b"))))
+	assert.True(t, ID(l) != ID(l.(Compound).Arg(1)))
+
+	p := PartialList(NewVariable(), NewAtom("a"))
+	assert.Equal(t, ID(p), ID(p))
+	assert.True(t, ID(p) != ID(PartialList(NewVariable(), NewAtom("a"))))
+
+	assert.Equal(t, ID(NewAtom("a")), ID(NewAtom("a")))
+	assert.Equal(t, ID(Integer(1)), ID(Integer(1)))
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"strings"
 )
@@ -23,13 +24,18 @@ func (vm *VM) Compile(ctx context.Context, s string, args ...interface{}) error
 		return err
 	}
 
-	if err := t.flush(); err != nil {
+	if err := t.flush(vm); err != nil {
 		return err
 	}
 
 	if vm.procedures == nil {
 		vm.procedures = map[procedureIndicator]procedure{}
 	}
+	for pi := range t.clauses {
+		if existing, ok := vm.procedures[pi].(*userDefined); ok && existing.frozen {
+			return permissionError(operationModify, permissionTypeStaticProcedure, pi.Term(), nil)
+		}
+	}
 	for pi, u := range t.clauses {
 		if existing, ok := vm.procedures[pi].(*userDefined); ok && existing.multifile && u.multifile {
 			existing.clauses = append(existing.clauses, u.clauses...)
@@ -89,9 +95,15 @@ func (vm *VM) compile(ctx context.Context, text *text, s string, args ...interfa
 	}
 
 	for p.More() {
-		p.Vars = p.Vars[:]
+		p.Vars = p.Vars[:0]
 		t, err := p.Term()
-		if err != nil {
+		switch err {
+		case nil:
+			break
+		case io.EOF:
+			// Unlike a query, the text is complete. It ended in the middle of a clause.
+			return io.ErrUnexpectedEOF
+		default:
 			return err
 		}
 
@@ -118,7 +130,7 @@ func (vm *VM) compile(ctx context.Context, text *text, s string, args ...interfa
 			fallthrough
 		default:
 			if len(text.buf) > 0 && pi != text.buf[0].pi {
-				if err := text.flush(); err != nil {
+				if err := text.flush(vm); err != nil {
 					return err
 				}
 			}
@@ -128,14 +140,47 @@ func (vm *VM) compile(ctx context.Context, text *text, s string, args ...interfa
 				return err
 			}
 
+			if err := vm.warnSingletons(ctx, t, p.Vars); err != nil {
+				return err
+			}
+
 			text.buf = append(text.buf, cs...)
 		}
 	}
 	return nil
 }
 
+// warnSingletons reports the named variables which appear only once in the clause unless style_check(-singleton).
+// The warning goes to message_hook/3 if defined, or to Singletons. Nothing is printed by default.
+func (vm *VM) warnSingletons(ctx context.Context, clause Term, vars []ParsedVariable) error {
+	if vm.noSingletonWarn {
+		return nil
+	}
+
+	var names []Atom
+	for _, v := range vars {
+		if v.Count == 1 && !strings.HasPrefix(v.Name.String(), "_") {
+			names = append(names, v.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	ns := make([]Term, len(names))
+	for i, n := range names {
+		ns[i] = n
+	}
+	_, err := printMessage(vm, atomWarning, atomSingletons.Apply(clause, List(ns...)), func(*Env) {
+		if vm.Singletons != nil {
+			vm.Singletons(clause, names)
+		}
+	}, Success, nil).Force(ctx)
+	return err
+}
+
 func (vm *VM) directive(ctx context.Context, text *text, d Term) error {
-	if err := text.flush(); err != nil {
+	if err := text.flush(vm); err != nil {
 		return err
 	}
 
@@ -263,7 +308,9 @@ func (t *text) forEachUserDefined(pi Term, f func(u *userDefined)) error {
 	return iter.Err()
 }
 
-func (t *text) flush() error {
+// flush adds the buffered clauses to their procedure.
+// It reports the clauses separated from the others of the same procedure unless it's discontiguous or style_check(-discontiguous).
+func (t *text) flush(vm *VM) error {
 	if len(t.buf) == 0 {
 		return nil
 	}
@@ -274,7 +321,7 @@ func (t *text) flush() error {
 		u = &userDefined{}
 		t.clauses[pi] = u
 	}
-	if len(u.clauses) > 0 && !u.discontiguous {
+	if len(u.clauses) > 0 && !u.discontiguous && !vm.noDiscontiguousCheck {
 		return &discontiguousError{pi: pi}
 	}
 	u.clauses = append(u.clauses, t.buf...)
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"errors"
@@ -20,6 +21,7 @@ func TestVM_Compile(t *testing.T) {
 		args   []interface{}
 		err    error
 		result map[procedureIndicator]procedure
+		stats  map[procedureIndicator]predicateStats
 	}{
 		{title: "shebang", text: `#!/foo/bar
 foo(a).
@@ -87,7 +89,7 @@ baz(X) :- bar(X).
 			},
 			{name: NewAtom("baz"), arity: 1}: &userDefined{
 				clauses: clauses{
-					{pi: procedureIndicator{name: NewAtom("baz"), arity: 1}, raw: atomIf.Apply(NewAtom("baz").Apply(lastVariable()+1), NewAtom("bar").Apply(lastVariable()+1)), xrTable: []Term{procedureIndicator{name: NewAtom("bar"), arity: 1}}, vars: []Variable{lastVariable() + 1}, bytecode: bytecode{
+					{pi: procedureIndicator{name: NewAtom("baz"), arity: 1}, raw: atomIf.Apply(NewAtom("baz").Apply(lastVariable()+2), NewAtom("bar").Apply(lastVariable()+2)), xrTable: []Term{procedureIndicator{name: NewAtom("bar"), arity: 1}}, vars: []Variable{lastVariable() + 2}, bytecode: bytecode{
 						{opcode: opVar, operand: 0},
 						{opcode: opEnter},
 						{opcode: opVar, operand: 0},
@@ -220,6 +222,8 @@ foo(b).
 					}},
 				},
 			},
+		}, stats: map[procedureIndicator]predicateStats{
+			{name: NewAtom("foo"), arity: 1}: {calls: 1},
 		}},
 		{title: "predicate-backed directive", text: `
 :- foo(c).
@@ -233,6 +237,8 @@ foo(b).
 					}},
 				},
 			},
+		}, stats: map[procedureIndicator]predicateStats{
+			{name: NewAtom("foo"), arity: 1}: {calls: 1},
 		}},
 
 		{title: "error: invalid argument", text: `
@@ -324,12 +330,164 @@ bar(b).
 			assert.Equal(t, tt.err, vm.Compile(context.Background(), tt.text, tt.args...))
 			if tt.err == nil {
 				delete(vm.procedures, procedureIndicator{name: NewAtom("throw"), arity: 1})
+
+				// Statistics are compared separately since they depend on execution rather than the text.
+				stats := map[procedureIndicator]predicateStats{}
+				for pi, p := range vm.procedures {
+					if u, ok := p.(*userDefined); ok && u.stats != (predicateStats{}) {
+						stats[pi] = u.stats
+						u.stats = predicateStats{}
+					}
+				}
+				if tt.stats == nil {
+					assert.Empty(t, stats)
+				} else {
+					assert.Equal(t, tt.stats, stats)
+				}
 				assert.Equal(t, tt.result, vm.procedures)
 			}
 		})
 	}
 }
 
+func TestVM_Compile_singletons(t *testing.T) {
+	var warned []Term
+	vm := VM{
+		procedures: map[procedureIndicator]procedure{
+			{name: atomMessageHook, arity: 3}: Predicate3(func(_ *VM, message, kind, _ Term, k Cont, env *Env) *Promise {
+				assert.Equal(t, atomWarning, env.Resolve(kind))
+				warned = append(warned, env.simplify(message).(Compound).Arg(1))
+				return k(env)
+			}),
+			{name: NewAtom("style_check"), arity: 1}: Predicate1(StyleCheck),
+		},
+		operators: operators{},
+	}
+	vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+	vm.operators.define(1200, operatorSpecifierFX, atomIf)
+	vm.operators.define(200, operatorSpecifierFY, atomPlus)
+	vm.operators.define(200, operatorSpecifierFY, atomMinus)
+	assert.NoError(t, vm.Compile(context.Background(), `
+foo(X).
+bar(X, _Y) :- baz(_).
+:- style_check(-singleton).
+baz(X).
+:- style_check(+singleton).
+qux(X, Y) :- quux(X, Z).
+`))
+	assert.Equal(t, []Term{
+		List(NewAtom("X")),
+		List(NewAtom("X")),
+		List(NewAtom("Y"), NewAtom("Z")),
+	}, warned)
+}
+
+func TestVM_Compile_singletonsCallback(t *testing.T) {
+	var buf bytes.Buffer
+
+	text := `
+foo(X).
+bar(X, Y) :- baz(Y).
+`
+
+	t.Run("default", func(t *testing.T) {
+		vm := VM{operators: operators{}}
+		vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+		vm.SetUserError(&buf)
+		assert.NoError(t, vm.Compile(context.Background(), text))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		var warned [][]Atom
+		vm := VM{
+			Singletons: func(_ Term, names []Atom) {
+				warned = append(warned, names)
+			},
+			operators: operators{},
+		}
+		vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+		vm.SetUserError(&buf)
+		assert.NoError(t, vm.Compile(context.Background(), text))
+		assert.Equal(t, [][]Atom{{NewAtom("X")}, {NewAtom("X")}}, warned)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestVM_Compile_discontiguous(t *testing.T) {
+	vm := VM{
+		procedures: map[procedureIndicator]procedure{
+			{name: NewAtom("style_check"), arity: 1}: Predicate1(StyleCheck),
+		},
+		operators: operators{},
+	}
+	vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+	vm.operators.define(1200, operatorSpecifierFX, atomIf)
+	vm.operators.define(200, operatorSpecifierFY, atomMinus)
+	vm.operators.define(200, operatorSpecifierFY, atomPlus)
+
+	assert.NoError(t, vm.Compile(context.Background(), `
+:- style_check(-discontiguous).
+foo(a).
+bar(a).
+foo(b).
+`))
+	assert.Len(t, vm.procedures[procedureIndicator{name: NewAtom("foo"), arity: 1}].(*userDefined).clauses, 2)
+
+	assert.Equal(t, &discontiguousError{pi: procedureIndicator{name: NewAtom("baz"), arity: 1}}, vm.Compile(context.Background(), `
+:- style_check(+discontiguous).
+baz(a).
+qux(a).
+baz(b).
+`))
+}
+
+func TestStyleCheck(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		var vm VM
+		ok, err := StyleCheck(&vm, atomMinus.Apply(atomSingleton), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, vm.noSingletonWarn)
+
+		ok, err = StyleCheck(&vm, atomPlus.Apply(atomSingleton), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, vm.noSingletonWarn)
+
+		ok, err = StyleCheck(&vm, atomMinus.Apply(atomDiscontiguous), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, vm.noDiscontiguousCheck)
+
+		ok, err = StyleCheck(&vm, atomPlus.Apply(atomDiscontiguous), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, vm.noDiscontiguousCheck)
+	})
+
+	t.Run("option is a variable", func(t *testing.T) {
+		var vm VM
+		ok, err := StyleCheck(&vm, NewVariable(), Success, nil).Force(context.Background())
+		assert.Equal(t, InstantiationError(nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("unknown style", func(t *testing.T) {
+		var vm VM
+		ok, err := StyleCheck(&vm, atomMinus.Apply(NewAtom("foo")), Success, nil).Force(context.Background())
+		assert.Equal(t, domainError(validDomainStyleName, NewAtom("foo"), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("neither + nor -", func(t *testing.T) {
+		var vm VM
+		ok, err := StyleCheck(&vm, atomSingleton, Success, nil).Force(context.Background())
+		assert.Equal(t, domainError(validDomainStyleName, atomSingleton, nil), err)
+		assert.False(t, ok)
+	})
+}
+
 func TestVM_Consult(t *testing.T) {
 	x := NewVariable()
 
@@ -344,8 +502,8 @@ func TestVM_Consult(t *testing.T) {
 		{title: `:- consult(['testdata/empty.txt']).`, files: List(NewAtom("testdata/empty.txt")), ok: true},
 		{title: `:- consult(['testdata/empty.txt', 'testdata/empty.txt']).`, files: List(NewAtom("testdata/empty.txt"), NewAtom("testdata/empty.txt")), ok: true},
 
-		{title: `:- consult('testdata/abc.txt').`, files: NewAtom("testdata/abc.txt"), err: io.EOF},
-		{title: `:- consult(['testdata/abc.txt']).`, files: List(NewAtom("testdata/abc.txt")), err: io.EOF},
+		{title: `:- consult('testdata/abc.txt').`, files: NewAtom("testdata/abc.txt"), err: io.ErrUnexpectedEOF},
+		{title: `:- consult(['testdata/abc.txt']).`, files: List(NewAtom("testdata/abc.txt")), err: io.ErrUnexpectedEOF},
 
 		{title: `:- consult(X).`, files: x, err: InstantiationError(nil)},
 		{title: `:- consult(foo(bar)).`, files: NewAtom("foo").Apply(NewAtom("bar")), err: typeError(validTypeAtom, NewAtom("foo").Apply(NewAtom("bar")), nil)},
@@ -9,13 +9,13 @@ import (
 var varCounter int64
 
 func lastVariable() Variable {
-	return Variable(varCounter)
+	return Variable(atomic.LoadInt64(&varCounter))
 }
 
 // Variable is a prolog variable.
 type Variable int64
 
-// NewVariable creates a new anonymous variable.
+// NewVariable creates a new anonymous variable which is written as _N.
 func NewVariable() Variable {
 	n := atomic.AddInt64(&varCounter, 1)
 	return Variable(n)
@@ -31,6 +31,9 @@ func (v Variable) WriteTerm(w io.Writer, opts *WriteOptions, env *Env) error {
 	if a, ok := opts.variableNames[v]; ok {
 		return a.WriteTerm(w, opts.withQuoted(false).withLeft(operator{}).withRight(operator{}), env)
 	}
+	if a, ok := opts.vm.variableName(v); ok {
+		return a.WriteTerm(w, opts.withQuoted(false).withLeft(operator{}).withRight(operator{}), env)
+	}
 	_, err := w.Write([]byte(fmt.Sprintf("_%d", v)))
 	return err
 }
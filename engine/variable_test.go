@@ -2,6 +2,7 @@ package engine
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"testing"
@@ -10,7 +11,8 @@ import (
 )
 
 func TestVariable_WriteTerm(t *testing.T) {
-	x := NewVariable()
+	var vm VM
+	x, y := NewVariable(), vm.NewNamedVariable("Y")
 
 	tests := []struct {
 		title  string
@@ -21,6 +23,9 @@ func TestVariable_WriteTerm(t *testing.T) {
 	}{
 		{title: "unnamed", v: x, output: fmt.Sprintf("_%d", x)},
 		{title: "variable_names", v: x, opts: WriteOptions{variableNames: map[Variable]Atom{x: NewAtom("Foo")}}, output: `Foo`},
+		{title: "named", v: y, opts: WriteOptions{vm: &vm}, output: `Y`},
+		{title: "named, variable_names", v: y, opts: WriteOptions{variableNames: map[Variable]Atom{y: NewAtom("Foo")}, vm: &vm}, output: `Foo`},
+		{title: "named, another VM", v: y, opts: WriteOptions{vm: &VM{}}, output: fmt.Sprintf("_%d", y)},
 	}
 
 	var buf bytes.Buffer
@@ -33,6 +38,39 @@ func TestVariable_WriteTerm(t *testing.T) {
 	}
 }
 
+func TestVM_NewNamedVariable(t *testing.T) {
+	var vm VM
+	x1, x2 := vm.NewNamedVariable("X"), vm.NewNamedVariable("X")
+	assert.NotEqual(t, x1, x2)
+
+	env, ok := NewEnv().Unify(x1, NewAtom("a"))
+	assert.True(t, ok)
+	assert.Equal(t, x2, env.Resolve(x2))
+
+	t.Run("write_term", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewOutputTextStream(&buf)
+		ok, err := WriteTerm(&vm, s, NewAtom("f").Apply(x1, x2), List(), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "f(X,X_1)", buf.String())
+	})
+
+	t.Run("term_to_atom", func(t *testing.T) {
+		a := NewVariable()
+		ok, err := TermToAtom(&vm, NewAtom("f").Apply(x1, x2), a, func(env *Env) *Promise {
+			assert.Equal(t, NewAtom("f(X,X_1)"), env.Resolve(a))
+			return Bool(true)
+		}, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("print_message", func(t *testing.T) {
+		assert.Equal(t, "Warning: f(X,X_1)", messageText(&vm, atomWarning, NewAtom("f").Apply(x1, x2), nil))
+	})
+}
+
 func TestVariable_Compare(t *testing.T) {
 	w, x, y := NewVariable(), NewVariable(), NewVariable()
 
@@ -6,6 +6,8 @@ import (
 	"io"
 	"io/fs"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type bytecode []instruction
@@ -44,10 +46,33 @@ func Failure(*Env) *Promise {
 // VM is the core of a Prolog interpreter. The zero value for VM is a valid VM without any builtin predicates.
 type VM struct {
 	// Unknown is a callback that is triggered when the VM reaches to an unknown predicate while current_prolog_flag(unknown, warning).
+	// It defaults to a no-op and is not triggered if message_hook/3 handles the warning.
 	Unknown func(name Atom, args []Term, env *Env)
 
-	procedures map[procedureIndicator]procedure
-	unknown    unknownAction
+	// Singletons is a callback that is triggered when the VM reads a clause with named variables which appear only once.
+	// It defaults to a no-op and is not triggered if message_hook/3 handles the warning.
+	Singletons func(clause Term, names []Atom)
+
+	// InferenceLimit is the maximum number of predicate calls in a query. 0 means unlimited.
+	// The call exceeding the limit raises resource_error(inference_limit_exceeded).
+	InferenceLimit uint64
+
+	// CaptureProofTree enables the VM to record the proof trees of the queries started with Env.CaptureProof.
+	// It's off by default so that the calls don't look for the proof tree under construction.
+	CaptureProofTree bool
+
+	// OnHalt is a callback that is triggered when the VM reaches to halt/1 before it stops the execution.
+	OnHalt func(code int)
+
+	// OnCall is a callback that is triggered when the VM calls a user-defined predicate.
+	OnCall func(name Atom, args []Term, env *Env)
+
+	// OnRedo is a callback that is triggered when the VM retries a user-defined predicate with the subsequent clause on backtracking.
+	OnRedo func(name Atom, args []Term, env *Env)
+
+	procedures  map[procedureIndicator]procedure
+	unknown     unknownAction
+	occursCheck occursCheck
 
 	// FS is a file system that is referenced when the VM loads Prolog texts e.g. ensure_loaded/1.
 	// It has no effect on open/4 nor open/3 which always access the actual file system.
@@ -59,13 +84,60 @@ type VM struct {
 	charConversions map[rune]rune
 	charConvEnabled bool
 	doubleQuotes    doubleQuotes
+	digitSeparators bool
 
 	// I/O
 	streams       streams
+	ioMu          sync.RWMutex // guards input and output.
 	input, output *Stream
 
+	// Variable names for display
+	varNamesMu   sync.RWMutex // guards varNames and varNamesUsed.
+	varNames     map[Variable]Atom
+	varNamesUsed map[Atom]struct{}
+
 	// Misc
-	debug bool
+	// Like the other flags, they're not safe to modify while queries are running concurrently.
+	debug                bool
+	noSingletonWarn      bool
+	noDiscontiguousCheck bool
+
+	// inferenceLimited is set once call_with_inference_limit/3 is called so that Arrive looks for the inference counter.
+	inferenceLimited atomic.Bool
+}
+
+// NewNamedVariable creates a new variable which the VM writes as name, e.g. by write/1.
+// The name is only for display: every call returns a distinct variable even with the same name.
+// To tell them apart, the later ones are written with a suffix, e.g. X, X_1, X_2, ...
+// The VM retains the name for its lifetime.
+func (vm *VM) NewNamedVariable(name string) Variable {
+	v := NewVariable()
+	vm.varNamesMu.Lock()
+	defer vm.varNamesMu.Unlock()
+	if vm.varNames == nil {
+		vm.varNames = map[Variable]Atom{}
+		vm.varNamesUsed = map[Atom]struct{}{}
+	}
+	a := NewAtom(name)
+	for i := 1; ; i++ {
+		if _, ok := vm.varNamesUsed[a]; !ok {
+			break
+		}
+		a = NewAtom(fmt.Sprintf("%s_%d", name, i))
+	}
+	vm.varNames[v] = a
+	vm.varNamesUsed[a] = struct{}{}
+	return v
+}
+
+func (vm *VM) variableName(v Variable) (Atom, bool) {
+	if vm == nil {
+		return 0, false
+	}
+	vm.varNamesMu.RLock()
+	defer vm.varNamesMu.RUnlock()
+	a, ok := vm.varNames[v]
+	return a, ok
 }
 
 // Register0 registers a predicate of arity 0.
@@ -156,15 +228,195 @@ func (u unknownAction) String() string {
 	}[u]
 }
 
+type occursCheck int
+
+const (
+	occursCheckFalse occursCheck = iota
+	occursCheckTrue
+	occursCheckError
+)
+
+func (o occursCheck) String() string {
+	return [...]string{
+		occursCheckFalse: "false",
+		occursCheckTrue:  "true",
+		occursCheckError: "error",
+	}[o]
+}
+
 type procedure interface {
 	call(*VM, []Term, Cont, *Env) *Promise
 }
 
+// PredStat is statistics of a user-defined predicate.
+type PredStat struct {
+	// Clauses is the number of the clauses.
+	Clauses int
+
+	// Indexed is true if the clauses are indexed by the first argument.
+	Indexed bool
+
+	// Calls is the number of times the predicate was called.
+	Calls int
+
+	// Redos is the number of times the predicate was retried with the subsequent clauses on backtracking.
+	Redos int
+}
+
+// PredicateStats returns statistics of the user-defined predicates keyed by their predicate indicators e.g. "foo/1".
+func (vm *VM) PredicateStats() map[string]PredStat {
+	ret := map[string]PredStat{}
+	for pi, p := range vm.procedures {
+		u, ok := p.(*userDefined)
+		if !ok {
+			continue
+		}
+		ret[pi.String()] = PredStat{
+			Clauses: len(u.clauses),
+			Indexed: u.index != nil,
+			Calls:   int(atomic.LoadInt64(&u.stats.calls)),
+			Redos:   int(atomic.LoadInt64(&u.stats.redos)),
+		}
+	}
+	return ret
+}
+
+// Memoize wraps the registered predicate of name and arity so that its answers are cached and reused for the subsequent
+// calls with the same arguments up to variable renaming. It's intended for expensive predicates without side effects.
+// The answers are cached only after a call has found all of them so that a call which stops early, e.g. by a cut, works
+// even for infinitely many answers. Dynamic predicates can't be memoized since their answers change by assert/retract.
+func (vm *VM) Memoize(name Atom, arity int) error {
+	pi := procedureIndicator{name: name, arity: Integer(arity)}
+	p, ok := vm.procedures[pi]
+	if !ok {
+		return existenceError(objectTypeProcedure, pi.Term(), nil)
+	}
+	if u, ok := p.(*userDefined); ok && u.dynamic {
+		return fmt.Errorf("can't memoize dynamic procedure: %s", pi)
+	}
+	vm.procedures[pi] = &memoized{procedure: p, answers: map[string][]Term{}}
+	return nil
+}
+
+// Freeze makes the user-defined predicates immutable and indexes their clauses by the first arguments for faster queries.
+// It's intended for a fixed knowledge base. Once frozen, the predicates can't be modified by assert/retract nor consult.
+func (vm *VM) Freeze() {
+	for pi, p := range vm.procedures {
+		if u, ok := p.(*userDefined); ok {
+			u.freeze(pi.arity)
+		}
+	}
+}
+
+type memoized struct {
+	procedure
+
+	mu      sync.Mutex
+	answers map[string][]Term
+}
+
+func (m *memoized) call(vm *VM, args []Term, k Cont, env *Env) *Promise {
+	key := m.key(args, env)
+	goal := tuple(args...)
+
+	m.mu.Lock()
+	answers, ok := m.answers[key]
+	m.mu.Unlock()
+
+	if !ok {
+		// Pass the answers through as they're found and cache them once there are no more.
+		var (
+			found []Term
+			p     *Promise
+		)
+		p = Delay(func(context.Context) *Promise {
+			return m.procedure.call(vm, args, func(env *Env) *Promise {
+				found = append(found, env.simplify(goal))
+				return Delay(func(ctx context.Context) *Promise {
+					// If it's the last answer, we don't have to wait for backtracking.
+					if !hasChoicesSince(ctx, p) {
+						m.cache(key, found)
+					}
+					return k(env)
+				})
+			}, env)
+		}, func(context.Context) *Promise {
+			m.cache(key, found)
+			return Bool(false)
+		})
+		return p
+	}
+
+	ks := make([]func(context.Context) *Promise, len(answers))
+	for i := range answers {
+		a := answers[i]
+		ks[i] = func(context.Context) *Promise {
+			c, err := renamedCopy(a, nil, env)
+			if err != nil {
+				return Error(err)
+			}
+			return Unify(vm, goal, c, k, env)
+		}
+	}
+	return Delay(ks...)
+}
+
+func (m *memoized) cache(key string, answers []Term) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.answers[key] = answers
+}
+
+// key returns a string which is the same for the arguments up to variable renaming.
+func (m *memoized) key(args []Term, env *Env) string {
+	var (
+		sb   strings.Builder
+		vars = map[Variable]int{}
+		opts = defaultWriteOptions.withQuoted(true)
+	)
+	var write func(Term)
+	write = func(t Term) {
+		switch t := env.Resolve(t).(type) {
+		case Variable:
+			n, ok := vars[t]
+			if !ok {
+				n = len(vars)
+				vars[t] = n
+			}
+			_, _ = fmt.Fprintf(&sb, "_%d", n)
+		case Compound:
+			_ = t.Functor().WriteTerm(&sb, opts, env)
+			_, _ = sb.WriteString("(")
+			for i := 0; i < t.Arity(); i++ {
+				if i > 0 {
+					_, _ = sb.WriteString(",")
+				}
+				write(t.Arg(i))
+			}
+			_, _ = sb.WriteString(")")
+		default:
+			_ = t.WriteTerm(&sb, opts, env)
+		}
+	}
+	for i, a := range args {
+		if i > 0 {
+			_, _ = sb.WriteString(",")
+		}
+		write(a)
+	}
+	return sb.String()
+}
+
 // Cont is a continuation.
 type Cont func(*Env) *Promise
 
 // Arrive is the entry point of the VM.
 func (vm *VM) Arrive(name Atom, args []Term, k Cont, env *Env) *Promise {
+	return vm.arrive(name, args, k, env, nil)
+}
+
+// arrive calls the procedure. If it's a last call, lc is the chain of last calls it belongs to.
+func (vm *VM) arrive(name Atom, args []Term, k Cont, env *Env, lc *lastCall) *Promise {
 	if vm.Unknown == nil {
 		vm.Unknown = func(Atom, []Term, *Env) {}
 	}
@@ -174,8 +426,9 @@ func (vm *VM) Arrive(name Atom, args []Term, k Cont, env *Env) *Promise {
 	if !ok {
 		switch vm.unknown {
 		case unknownWarning:
-			vm.Unknown(name, args, env)
-			fallthrough
+			return printMessage(vm, atomWarning, existenceError(objectTypeProcedure, pi.Term(), env).term, func(env *Env) {
+				vm.Unknown(name, args, env)
+			}, Failure, env)
 		case unknownFail:
 			return Bool(false)
 		default:
@@ -186,6 +439,34 @@ func (vm *VM) Arrive(name Atom, args []Term, k Cont, env *Env) *Promise {
 	// bind the special variable to inform the predicate about the context.
 	env = env.bind(varContext, pi.Term())
 
+	// look up the inference counter only if there can be one.
+	if vm.InferenceLimit > 0 || vm.inferenceLimited.Load() {
+		c, ok := env.inferenceCounter()
+		if !ok && vm.InferenceLimit > 0 {
+			env, c = env.limitInferences(vm.InferenceLimit)
+			ok = true
+		}
+		if ok && !c.infer() {
+			return Error(resourceError(resourceInferenceLimitExceeded, env))
+		}
+	}
+
+	if u, ok := p.(*userDefined); ok {
+		if vm.OnCall != nil {
+			vm.OnCall(name, args, env)
+		}
+		if vm.CaptureProofTree {
+			goal, err := pi.Apply(args...)
+			if err != nil {
+				return Error(err)
+			}
+			return p.call(vm, args, func(env *Env) *Promise {
+				return k(exitProof(env))
+			}, enterProof(goal, env))
+		}
+		return u.exec(vm, args, k, env, lc)
+	}
+
 	return p.call(vm, args, k, env)
 }
 
@@ -198,6 +479,7 @@ type registers struct {
 
 	env       *Env
 	cutParent *Promise
+	lastCall  *lastCall
 }
 
 func (r *registers) updateEnv(e *Env) *Promise {
@@ -323,6 +605,17 @@ func (vm *VM) execCall(r *registers) *Promise {
 	}
 	r.pc = r.pc[1:]
 	args, _ := slice(r.astack, r.env)
+
+	// Last call optimization: the callee continues with our continuation so that the frame doesn't pile up.
+	// The bindings only our frame refers to are dropped as well.
+	if r.pc[0].opcode == opExit {
+		env, lc := r.env, r.lastCall
+		if !vm.CaptureProofTree {
+			env, lc = lc.gc(env, args)
+		}
+		return vm.arrive(pi.name, args, r.cont, env, lc)
+	}
+
 	return vm.Arrive(pi.name, args, func(env *Env) *Promise {
 		v := NewVariable()
 		return vm.exec(registers{
@@ -334,6 +627,7 @@ func (vm *VM) execCall(r *registers) *Promise {
 			astack:    v,
 			env:       env,
 			cutParent: r.cutParent,
+			lastCall:  r.lastCall,
 		})
 	}, r.env)
 }
@@ -354,6 +648,7 @@ func (vm *VM) execCut(r *registers) *Promise {
 			astack:    r.astack,
 			env:       r.env,
 			cutParent: r.cutParent,
+			lastCall:  r.lastCall,
 		})
 	})
 }
@@ -383,26 +678,67 @@ func (vm *VM) execPartial(r *registers) *Promise {
 	}
 	prefix, tail := NewVariable(), NewVariable()
 	_, _ = Length(vm, prefix, l, r.updateEnv, r.env).Force(context.Background())
-	_, _ = Append(vm, prefix, tail, arg, r.updateEnv, r.env).Force(context.Background())
+	ok, err := Append(vm, prefix, tail, arg, r.updateEnv, r.env).Force(context.Background())
+	if err != nil {
+		return Error(err)
+	}
+	if !ok {
+		return Bool(false)
+	}
 	r.pc = r.pc[1:]
 	r.args = Cons(tail, prefix)
 	r.astack = Cons(arest, r.astack)
 	return nil
 }
 
-// SetUserInput sets the given stream as user_input.
+// SetUserInput sets the given stream as user_input and the current input stream.
+// It's safe to call while queries are running. Reads already in progress keep using the previous stream.
 func (vm *VM) SetUserInput(s *Stream) {
 	s.vm = vm
 	s.alias = atomUserInput
 	vm.streams.add(s)
-	vm.input = s
+	vm.setInput(s)
 }
 
-// SetUserOutput sets the given stream as user_output.
+// SetUserOutput sets the given stream as user_output and the current output stream.
+// It's safe to call while queries are running. Writes already in progress keep using the previous stream.
 func (vm *VM) SetUserOutput(s *Stream) {
 	s.vm = vm
 	s.alias = atomUserOutput
 	vm.streams.add(s)
+	vm.setOutput(s)
+}
+
+// SetUserError sets the given io.Writer as user_error where the VM prints messages e.g. warnings.
+// It's safe to call while queries are running.
+func (vm *VM) SetUserError(w io.Writer) {
+	s := NewOutputTextStream(w)
+	s.vm = vm
+	s.alias = atomUserError
+	vm.streams.add(s)
+}
+
+func (vm *VM) currentInput() *Stream {
+	vm.ioMu.RLock()
+	defer vm.ioMu.RUnlock()
+	return vm.input
+}
+
+func (vm *VM) setInput(s *Stream) {
+	vm.ioMu.Lock()
+	defer vm.ioMu.Unlock()
+	vm.input = s
+}
+
+func (vm *VM) currentOutput() *Stream {
+	vm.ioMu.RLock()
+	defer vm.ioMu.RUnlock()
+	return vm.output
+}
+
+func (vm *VM) setOutput(s *Stream) {
+	vm.ioMu.Lock()
+	defer vm.ioMu.Unlock()
 	vm.output = s
 }
 
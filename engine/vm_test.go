@@ -1,8 +1,12 @@
 package engine
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -190,6 +194,80 @@ func TestVM_Register8(t *testing.T) {
 	})
 }
 
+func TestVM_PredicateStats(t *testing.T) {
+	newVM := func() *VM {
+		vm := VM{operators: operators{}}
+		vm.operators.define(1200, operatorSpecifierXFX, atomIf)
+		vm.Register0(atomTrue, func(_ *VM, k Cont, env *Env) *Promise {
+			return k(env)
+		})
+		assert.NoError(t, vm.Compile(context.Background(), `
+foo(a).
+foo(b).
+foo(c).
+bar(X) :- foo(X).
+`))
+		return &vm
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		vm := newVM()
+
+		var calls, redos []string
+		vm.OnCall = func(name Atom, args []Term, _ *Env) {
+			calls = append(calls, fmt.Sprintf("%s/%d", name, len(args)))
+		}
+		vm.OnRedo = func(name Atom, args []Term, _ *Env) {
+			redos = append(redos, fmt.Sprintf("%s/%d", name, len(args)))
+		}
+
+		ok, err := Call(vm, NewAtom("bar").Apply(NewVariable()), Failure, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = Call(vm, NewAtom("foo").Apply(NewAtom("a")), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.Equal(t, map[string]PredStat{
+			"foo/1": {Clauses: 3, Calls: 2, Redos: 2},
+			"bar/1": {Clauses: 1, Calls: 1, Redos: 0},
+		}, vm.PredicateStats())
+		assert.Equal(t, []string{"bar/1", "foo/1", "foo/1"}, calls)
+		assert.Equal(t, []string{"foo/1", "foo/1"}, redos)
+	})
+
+	t.Run("indexed", func(t *testing.T) {
+		vm := newVM()
+		vm.Freeze()
+
+		stats := vm.PredicateStats()
+		assert.True(t, stats["foo/1"].Indexed)
+		assert.True(t, stats["bar/1"].Indexed)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		vm := newVM()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, _ = Call(vm, NewAtom("bar").Apply(NewVariable()), Failure, nil).Force(context.Background())
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, map[string]PredStat{
+			"foo/1": {Clauses: 3, Calls: 1000, Redos: 2000},
+			"bar/1": {Clauses: 1, Calls: 1000, Redos: 0},
+		}, vm.PredicateStats())
+	})
+}
+
 func TestVM_Arrive(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		vm := VM{
@@ -204,6 +282,26 @@ func TestVM_Arrive(t *testing.T) {
 		assert.True(t, ok)
 	})
 
+	t.Run("proof tree", func(t *testing.T) {
+		var vm VM
+		assert.NoError(t, vm.Compile(context.Background(), `foo(a).`))
+		goal := NewAtom("foo").Apply(NewAtom("a"))
+
+		for _, captured := range []bool{false, true} {
+			vm.CaptureProofTree = captured
+			ok, err := vm.Arrive(NewAtom("foo"), []Term{NewAtom("a")}, func(env *Env) *Promise {
+				var children []*ProofNode
+				if captured {
+					children = []*ProofNode{{Goal: goal, Clause: goal}}
+				}
+				assert.Equal(t, &ProofNode{Goal: goal, Children: children}, env.ProofTree())
+				return Bool(true)
+			}, NewEnv().CaptureProof(goal)).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+	})
+
 	t.Run("unknown procedure", func(t *testing.T) {
 		t.Run("error", func(t *testing.T) {
 			vm := VM{
@@ -234,6 +332,42 @@ func TestVM_Arrive(t *testing.T) {
 			assert.True(t, warned)
 		})
 
+		t.Run("warning without callback", func(t *testing.T) {
+			var buf bytes.Buffer
+			vm := VM{
+				unknown: unknownWarning,
+			}
+			vm.SetUserError(&buf)
+			ok, err := vm.Arrive(NewAtom("foo"), []Term{NewAtom("a")}, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.Empty(t, buf.String())
+		})
+
+		t.Run("message hook", func(t *testing.T) {
+			var captured Term
+			vm := VM{
+				unknown: unknownWarning,
+				Unknown: func(Atom, []Term, *Env) {
+					assert.Fail(t, "unreachable")
+				},
+				procedures: map[procedureIndicator]procedure{
+					{name: atomMessageHook, arity: 3}: Predicate3(func(_ *VM, message, kind, lines Term, k Cont, env *Env) *Promise {
+						assert.Equal(t, atomWarning, env.Resolve(kind))
+						captured = env.simplify(message)
+						return k(env)
+					}),
+				},
+			}
+			ok, err := vm.Arrive(NewAtom("foo"), []Term{NewAtom("a")}, Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.Equal(t, existenceError(objectTypeProcedure, &compound{
+				functor: atomSlash,
+				args:    []Term{NewAtom("foo"), Integer(1)},
+			}, nil).term, captured)
+		})
+
 		t.Run("fail", func(t *testing.T) {
 			vm := VM{
 				unknown: unknownFail,
@@ -245,6 +379,196 @@ func TestVM_Arrive(t *testing.T) {
 	})
 }
 
+func TestVM_Memoize(t *testing.T) {
+	var calls int
+	var vm VM
+	vm.Register2(NewAtom("double"), func(vm *VM, x, y Term, k Cont, env *Env) *Promise {
+		calls++
+		n, ok := env.Resolve(x).(Integer)
+		if !ok {
+			return Bool(false)
+		}
+		return Unify(vm, y, 2*n, k, env)
+	})
+	vm.Register1(NewAtom("digit"), func(vm *VM, x Term, k Cont, env *Env) *Promise {
+		calls++
+		return Delay(func(context.Context) *Promise {
+			return Unify(vm, x, Integer(0), k, env)
+		}, func(context.Context) *Promise {
+			return Unify(vm, x, Integer(1), k, env)
+		})
+	})
+	var nat func(vm *VM, x Term, n Integer, k Cont, env *Env) *Promise
+	nat = func(vm *VM, x Term, n Integer, k Cont, env *Env) *Promise {
+		return Delay(func(context.Context) *Promise {
+			return Unify(vm, x, n, k, env)
+		}, func(context.Context) *Promise {
+			return nat(vm, x, n+1, k, env)
+		})
+	}
+	vm.Register1(NewAtom("nat"), func(vm *VM, x Term, k Cont, env *Env) *Promise {
+		calls++
+		return nat(vm, x, 0, k, env)
+	})
+	assert.NoError(t, vm.Memoize(NewAtom("double"), 2))
+	assert.NoError(t, vm.Memoize(NewAtom("digit"), 1))
+	assert.NoError(t, vm.Memoize(NewAtom("nat"), 1))
+
+	t.Run("cached", func(t *testing.T) {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			y := NewVariable()
+			ok, err := vm.Arrive(NewAtom("double"), []Term{Integer(21), y}, func(env *Env) *Promise {
+				assert.Equal(t, Integer(42), env.Resolve(y))
+				return Bool(true)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+		assert.Equal(t, 1, calls)
+
+		ok, err := vm.Arrive(NewAtom("double"), []Term{Integer(21), Integer(43)}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("multiple answers", func(t *testing.T) {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			x := NewVariable()
+			var answers []Term
+			ok, err := vm.Arrive(NewAtom("digit"), []Term{x}, func(env *Env) *Promise {
+				answers = append(answers, env.Resolve(x))
+				return Bool(false)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.Equal(t, []Term{Integer(0), Integer(1)}, answers)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("infinitely many answers", func(t *testing.T) {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			x := NewVariable()
+			ok, err := vm.Arrive(NewAtom("nat"), []Term{x}, func(env *Env) *Promise {
+				return Bool(env.Resolve(x) == Integer(3))
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+		// The answers are incomplete and not cached.
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("unknown procedure", func(t *testing.T) {
+		assert.Equal(t, existenceError(objectTypeProcedure, atomSlash.Apply(NewAtom("foo"), Integer(1)), nil), vm.Memoize(NewAtom("foo"), 1))
+	})
+
+	t.Run("dynamic procedure", func(t *testing.T) {
+		vm := VM{
+			procedures: map[procedureIndicator]procedure{
+				{name: NewAtom("foo"), arity: 1}: &userDefined{dynamic: true},
+			},
+		}
+		vm.Register1(NewAtom("assertz"), Assertz)
+		assert.Error(t, vm.Memoize(NewAtom("foo"), 1))
+
+		ok, err := vm.Arrive(NewAtom("assertz"), []Term{NewAtom("foo").Apply(NewAtom("b"))}, Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestVM_Freeze(t *testing.T) {
+	vm := vmWithOperators(operator{priority: 1200, specifier: operatorSpecifierXFX, name: atomIf})
+	vm.Register0(atomTrue, func(_ *VM, k Cont, env *Env) *Promise {
+		return k(env)
+	})
+	assert.NoError(t, vm.Compile(context.Background(), `
+foo(a, 1).
+foo(_, 2).
+foo(b, 3).
+foo(f(a), 4).
+foo(a, 5).
+bar :- true.
+`))
+	vm.Freeze()
+
+	t.Run("query", func(t *testing.T) {
+		tests := []struct {
+			title string
+			first Term
+			ns    []Integer
+		}{
+			{title: "atom", first: NewAtom("a"), ns: []Integer{1, 2, 5}},
+			{title: "compound", first: NewAtom("f").Apply(NewVariable()), ns: []Integer{2, 4}},
+			{title: "not indexed", first: NewAtom("c"), ns: []Integer{2}},
+			{title: "variable", first: NewVariable(), ns: []Integer{1, 2, 3, 4, 5}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.title, func(t *testing.T) {
+				var ns []Integer
+				n := NewVariable()
+				_, err := Call(vm, NewAtom("foo").Apply(tt.first, n), func(env *Env) *Promise {
+					ns = append(ns, env.Resolve(n).(Integer))
+					return Bool(false)
+				}, nil).Force(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ns, ns)
+			})
+		}
+
+		ok, err := Call(vm, NewAtom("bar"), Success, nil).Force(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("assertz", func(t *testing.T) {
+		ok, err := Assertz(vm, NewAtom("foo").Apply(NewAtom("c"), Integer(6)), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, atomSlash.Apply(NewAtom("foo"), Integer(2)), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("retract", func(t *testing.T) {
+		ok, err := Retract(vm, NewAtom("foo").Apply(NewAtom("a"), Integer(1)), Success, nil).Force(context.Background())
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, atomSlash.Apply(NewAtom("foo"), Integer(2)), nil), err)
+		assert.False(t, ok)
+	})
+
+	t.Run("compile", func(t *testing.T) {
+		assert.Equal(t, permissionError(operationModify, permissionTypeStaticProcedure, atomSlash.Apply(NewAtom("bar"), Integer(0)), nil), vm.Compile(context.Background(), `bar.`))
+	})
+}
+
+func BenchmarkVM_Freeze(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		_, _ = fmt.Fprintf(&sb, "foo(%d, x).\n", i)
+	}
+
+	for _, frozen := range []bool{false, true} {
+		var vm VM
+		if err := vm.Compile(context.Background(), sb.String()); err != nil {
+			b.Fatal(err)
+		}
+		if frozen {
+			vm.Freeze()
+		}
+		b.Run(fmt.Sprintf("frozen=%t", frozen), func(b *testing.B) {
+			goal := NewAtom("foo").Apply(Integer(500), NewVariable())
+			for i := 0; i < b.N; i++ {
+				if _, err := Call(&vm, goal, Success, nil).Force(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestVM_SetUserInput(t *testing.T) {
 	t.Run("file", func(t *testing.T) {
 		var vm VM
@@ -265,6 +589,50 @@ func TestVM_SetUserOutput(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, os.Stdout, s.sink)
 	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var vm VM
+		vm.SetUserOutput(NewOutputTextStream(&bytes.Buffer{}))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				vm.SetUserOutput(NewOutputTextStream(&bytes.Buffer{}))
+			}
+		}()
+
+		for i := 0; i < 100; i++ {
+			s := NewVariable()
+			ok, err := CurrentOutput(&vm, s, func(env *Env) *Promise {
+				return SetOutput(&vm, env.Resolve(s), Success, env)
+			}, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = StreamProperty(&vm, NewVariable(), atomAlias.Apply(atomUserOutput), Success, nil).Force(context.Background())
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestVM_SetUserError(t *testing.T) {
+	var buf bytes.Buffer
+	var vm VM
+	vm.SetUserError(&buf)
+
+	s, ok := vm.streams.lookup(atomUserError)
+	assert.True(t, ok)
+	assert.Equal(t, &buf, s.sink)
+
+	ok, err := PrintMessage(&vm, atomWarning, NewAtom("foo"), Success, nil).Force(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Warning: foo\n", buf.String())
 }
 
 func TestProcedureIndicator_Apply(t *testing.T) {
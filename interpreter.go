@@ -26,6 +26,7 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.FS = defaultFS{}
 	i.SetUserInput(engine.NewInputTextStream(in))
 	i.SetUserOutput(engine.NewOutputTextStream(out))
+	i.SetUserError(os.Stderr)
 
 	// Control constructs
 	i.Register1(engine.NewAtom("call"), engine.Call)
@@ -44,10 +45,15 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register1(engine.NewAtom("float"), engine.TypeFloat)
 	i.Register1(engine.NewAtom("compound"), engine.TypeCompound)
 	i.Register1(engine.NewAtom("acyclic_term"), engine.AcyclicTerm)
+	i.Register1(engine.NewAtom("ground"), engine.Ground)
 
 	// Term comparison
 	i.Register3(engine.NewAtom("compare"), engine.Compare)
+	i.Register2(engine.NewAtom("=@="), engine.Variant)
 	i.Register2(engine.NewAtom("sort"), engine.Sort)
+	i.Register2(engine.NewAtom("msort"), engine.MSort)
+	i.Register4(engine.NewAtom("sort"), engine.Sort4)
+	i.Register3(engine.NewAtom("predsort"), engine.PredSort)
 	i.Register2(engine.NewAtom("keysort"), engine.KeySort)
 
 	// Term creation and decomposition
@@ -55,6 +61,7 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register3(engine.NewAtom("arg"), engine.Arg)
 	i.Register2(engine.NewAtom("=.."), engine.Univ)
 	i.Register2(engine.NewAtom("copy_term"), engine.CopyTerm)
+	i.Register2(engine.NewAtom("rename_term"), engine.RenameTerm)
 	i.Register2(engine.NewAtom("term_variables"), engine.TermVariables)
 
 	// Arithmetic evaluation
@@ -76,10 +83,13 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register1(engine.NewAtom("asserta"), engine.Asserta)
 	i.Register1(engine.NewAtom("assertz"), engine.Assertz)
 	i.Register1(engine.NewAtom("retract"), engine.Retract)
+	i.Register1(engine.NewAtom("retractall"), engine.RetractAll)
 	i.Register1(engine.NewAtom("abolish"), engine.Abolish)
+	i.Register1(engine.NewAtom("dynamic"), engine.Dynamic)
 
 	// All solutions
 	i.Register3(engine.NewAtom("findall"), engine.FindAll)
+	i.Register4(engine.NewAtom("findall"), engine.FindAll4)
 	i.Register3(engine.NewAtom("bagof"), engine.BagOf)
 	i.Register3(engine.NewAtom("setof"), engine.SetOf)
 
@@ -98,6 +108,7 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register2(engine.NewAtom("get_char"), engine.GetChar)
 	i.Register2(engine.NewAtom("peek_char"), engine.PeekChar)
 	i.Register2(engine.NewAtom("put_char"), engine.PutChar)
+	i.Register2(engine.NewAtom("tab"), engine.Tab)
 
 	// Byte input/output
 	i.Register2(engine.NewAtom("get_byte"), engine.GetByte)
@@ -114,6 +125,9 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 
 	// Logic and control
 	i.Register1(engine.NewAtom(`\+`), engine.Negate)
+	i.Register1(engine.NewAtom("not"), engine.Negate)
+	i.Register3(engine.NewAtom("$soft_cut"), engine.SoftCut)
+	i.Register2(engine.NewAtom("forall"), engine.ForAll)
 	i.Register0(engine.NewAtom("repeat"), engine.Repeat)
 	i.Register2(engine.NewAtom("call"), engine.Call1)
 	i.Register3(engine.NewAtom("call"), engine.Call2)
@@ -127,16 +141,22 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register2(engine.NewAtom("atom_length"), engine.AtomLength)
 	i.Register3(engine.NewAtom("atom_concat"), engine.AtomConcat)
 	i.Register5(engine.NewAtom("sub_atom"), engine.SubAtom)
+	i.Register4(engine.NewAtom("split_string"), engine.SplitString)
 	i.Register2(engine.NewAtom("atom_chars"), engine.AtomChars)
 	i.Register2(engine.NewAtom("atom_codes"), engine.AtomCodes)
 	i.Register2(engine.NewAtom("char_code"), engine.CharCode)
 	i.Register2(engine.NewAtom("number_chars"), engine.NumberChars)
 	i.Register2(engine.NewAtom("number_codes"), engine.NumberCodes)
+	i.Register2(engine.NewAtom("atom_number"), engine.AtomNumber)
+	i.Register2(engine.NewAtom("term_to_atom"), engine.TermToAtom)
+	i.Register3(engine.NewAtom("read_term_from_atom"), engine.ReadTermFromAtom)
 
 	// Implementation defined hooks
 	i.Register2(engine.NewAtom("set_prolog_flag"), engine.SetPrologFlag)
 	i.Register2(engine.NewAtom("current_prolog_flag"), engine.CurrentPrologFlag)
 	i.Register1(engine.NewAtom("halt"), engine.Halt)
+	i.Register2(engine.NewAtom("print_message"), engine.PrintMessage)
+	i.Register1(engine.NewAtom("style_check"), engine.StyleCheck)
 
 	// Consult
 	i.Register1(engine.NewAtom("consult"), engine.Consult)
@@ -150,9 +170,14 @@ func New(in io.Reader, out io.Writer) *Interpreter {
 	i.Register2(engine.NewAtom("length"), engine.Length)
 	i.Register3(engine.NewAtom("between"), engine.Between)
 	i.Register2(engine.NewAtom("succ"), engine.Succ)
+	i.Register3(engine.NewAtom("plus"), engine.Plus)
 	i.Register3(engine.NewAtom("nth0"), engine.Nth0)
 	i.Register3(engine.NewAtom("nth1"), engine.Nth1)
+	i.Register2(engine.NewAtom("last"), engine.Last)
+	i.Register2(engine.NewAtom("reverse"), engine.Reverse)
 	i.Register2(engine.NewAtom("call_nth"), engine.CallNth)
+	i.Register3(engine.NewAtom("call_with_inference_limit"), engine.CallWithInferenceLimit)
+	i.Register4(engine.NewAtom("call_with_inference_limit"), engine.CallWithInferenceLimit4)
 
 	_ = i.Exec(bootstrap)
 
@@ -175,6 +200,7 @@ func (i *Interpreter) Query(query string, args ...interface{}) (*Solutions, erro
 }
 
 // QueryContext executes a prolog query and returns *Solutions with context.
+// Once ctx is done, the execution stops at the next choice point and Solutions.Err reports ctx.Err().
 func (i *Interpreter) QueryContext(ctx context.Context, query string, args ...interface{}) (*Solutions, error) {
 	p := engine.NewParser(&i.VM, strings.NewReader(query))
 	if err := p.SetPlaceholder(engine.NewAtom("?"), args...); err != nil {
@@ -187,6 +213,9 @@ func (i *Interpreter) QueryContext(ctx context.Context, query string, args ...in
 	}
 
 	var env *engine.Env
+	if i.CaptureProofTree {
+		env = env.CaptureProof(t)
+	}
 
 	more := make(chan bool, 1)
 	next := make(chan *engine.Env)
@@ -238,6 +267,32 @@ func (i *Interpreter) QuerySolutionContext(ctx context.Context, query string, ar
 	return &Solution{sols: sols, err: sols.Close()}
 }
 
+// QueryOnce executes a Prolog query and returns the bindings of the first solution keyed by the variable names.
+// It returns ErrNoSolutions if there's no solutions.
+func (i *Interpreter) QueryOnce(query string, args ...interface{}) (map[string]engine.Term, error) {
+	return i.QueryOnceContext(context.Background(), query, args...)
+}
+
+// QueryOnceContext executes a Prolog query with context and returns the bindings of the first solution.
+func (i *Interpreter) QueryOnceContext(ctx context.Context, query string, args ...interface{}) (map[string]engine.Term, error) {
+	sols, err := i.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = sols.Close()
+	}()
+
+	if !sols.Next() {
+		if err := sols.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoSolutions
+	}
+
+	return sols.bindings()
+}
+
 type defaultFS struct{}
 
 func (d defaultFS) Open(name string) (fs.File, error) {
@@ -9,7 +9,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -760,6 +765,123 @@ append(nil, L, L).`},
 	}
 }
 
+func TestInterpreter_Exec_missingFullStop(t *testing.T) {
+	i := New(nil, nil)
+	assert.Equal(t, io.ErrUnexpectedEOF, i.Exec(`foo(a)`))
+	assert.Equal(t, io.ErrUnexpectedEOF, i.Exec(`foo(a). bar(`))
+
+	// A query can be continued with more input.
+	_, err := i.Query(`foo(a)`)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestInterpreter_lastCall(t *testing.T) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	i := New(nil, nil)
+	i.Register0(engine.NewAtom("measure"), func(_ *engine.VM, k engine.Cont, env *engine.Env) *engine.Promise {
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		return k(env)
+	})
+	assert.NoError(t, i.Exec(`
+count(0, X) :- !, measure, X = done.
+count(N, X) :- N1 is N - 1, count(N1, X).
+`))
+
+	// Without the last call optimization, the bindings of every iteration would pile up.
+	var s struct {
+		X string
+	}
+	assert.NoError(t, i.QuerySolution(`count(100000, X).`).Scan(&s))
+	assert.Equal(t, "done", s.X)
+	assert.Less(t, int64(after.HeapAlloc)-int64(before.HeapAlloc), int64(10*1024*1024))
+}
+
+func TestInterpreter_concurrentIO(t *testing.T) {
+	var out, errOut lockedBuffer
+	i := New(nil, &out)
+	i.SetUserError(&errOut)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, i.QuerySolution(`write(foo), nl, current_output(S), set_output(S), print_message(error, bar).`).Err())
+			}
+		}()
+	}
+
+	// Swap the streams while the queries are writing to them.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 50; j++ {
+			i.SetUserOutput(engine.NewOutputTextStream(&out))
+			i.SetUserError(&errOut)
+		}
+	}()
+
+	wg.Wait()
+	assert.Equal(t, strings.Repeat("foo\n", 200), out.String())
+	assert.Equal(t, 200, strings.Count(errOut.String(), "bar"))
+
+	t.Run("read", func(t *testing.T) {
+		input := strings.Repeat("abcd", 50)
+		i := New(strings.NewReader(input), nil)
+
+		var (
+			mu   sync.Mutex
+			read []string
+			wg   sync.WaitGroup
+		)
+		for n := 0; n < 4; n++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					var s struct {
+						C string
+					}
+					assert.NoError(t, i.QuerySolution(`get_char(C).`).Scan(&s))
+					mu.Lock()
+					read = append(read, s.C)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Every character is read exactly once.
+		sort.Strings(read)
+		expected := strings.Split(input, "")
+		sort.Strings(expected)
+		assert.Equal(t, expected, read)
+	})
+}
+
+// lockedBuffer is a bytes.Buffer which can be written concurrently.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestInterpreter_Query(t *testing.T) {
 	type result struct {
 		A    string
@@ -1176,6 +1298,656 @@ next(N) :- retract(count(X)), N is X + 1, asserta(count(N)).
 		assert.NoError(t, sols.Err())
 		assert.NoError(t, sols.Close())
 	})
+
+	t.Run("retract while iterating", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`
+:- dynamic(foo/1).
+foo(a).
+foo(b).
+foo(c).
+`))
+
+		var s struct {
+			L, M []string
+		}
+		sol := i.QuerySolution(`findall(X, (foo(X), retract(foo(X))), L), findall(X, foo(X), M).`)
+		assert.NoError(t, sol.Scan(&s))
+		assert.Equal(t, []string{"a", "b", "c"}, s.L)
+		assert.Empty(t, s.M)
+	})
+
+	t.Run("operator-heavy clause round trip", func(t *testing.T) {
+		for _, body := range []string{
+			`a = b, c is d + e * f`,
+			`(a ; b) -> c ; d`,
+			`\+ a, - (1) = -1, 1 - (2 - 3) =:= 2`,
+			`a = (b :- c), d = (e, f), g = (h -> i ; j)`,
+			`x is - (- 1), y is - - 1, z is -(1)`,
+			`x = (a :- b, c ; d), y = [a|b], z = {a, b}`,
+			`x = - (-(1)), y = 1 - -1, z = a- (-1)`,
+			`x = (a = b) = c, y = 2 ** 3 ** 4, z = 2 ^ 3 ^ 4`,
+			`x = f((a, b)), y = [(a :- b)], z = f(;, '|', [], {})`,
+			`x = -(0), y = -(0.0), z = -0.0`,
+			`x = {[a, b]}, y = [{a}, {b}], z = {{[{a}]}}`,
+		} {
+			t.Run(body, func(t *testing.T) {
+				var out bytes.Buffer
+				p := New(nil, &out)
+				assert.NoError(t, p.Exec(`:- dynamic(p/0).`))
+				assert.NoError(t, p.QuerySolution(`assertz((p :- `+body+`)), clause(p, B), write_term((p :- B), [quoted(true), fullstop(true)]).`).Err())
+
+				q := New(strings.NewReader(out.String()), nil)
+				assert.NoError(t, q.QuerySolution(`read(T), T == (p :- `+body+`).`).Err(), out.String())
+			})
+		}
+	})
+
+	t.Run("assert", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`assert(foo(a)), assert(foo(b)), findall(X, foo(X), [a, b]).`).Err())
+	})
+
+	t.Run("op/3 affects the writer", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		assert.NoError(t, i.QuerySolution(`op(700, xfx, <--), write(<--(a, b)), nl, writeq('<--'(c, d)).`).Err())
+		assert.Equal(t, "a<--b\nc<--d", out.String())
+
+		out.Reset()
+		assert.NoError(t, i.QuerySolution(`op(0, xfx, <--), write(<--(a, b)).`).Err())
+		assert.Equal(t, "<--(a,b)", out.String())
+	})
+
+	t.Run("tab/1 and put_char/1", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		assert.NoError(t, i.QuerySolution(`put_char(a), tab(2+1), put_char(b).`).Err())
+		assert.Equal(t, "a   b", out.String())
+
+		assert.NoError(t, i.QuerySolution(`catch(tab(1.0), error(type_error(integer, 1.0), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(tab(-1), error(domain_error(not_less_than_zero, -1), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(put_char(ab), error(type_error(character, ab), _), true).`).Err())
+	})
+
+	t.Run("findall/4", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`findall(X, member(X, [1, 2]), L, [end]), L == [1, 2|[end]].`).Err())
+		assert.NoError(t, i.QuerySolution(`findall(X, member(X, [1, 2]), L, T), findall(Y, member(Y, [3]), T, []), L == [1, 2, 3].`).Err())
+	})
+
+	t.Run("write family", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		assert.NoError(t, i.QuerySolution(`write('a b'+'$VAR'(1)), nl, print('a b'+'$VAR'(1)), nl(user_output), write_canonical('a b'+'$VAR'(1)), writeln(end).`).Err())
+		assert.Equal(t, "a b+B\n'a b'+B\n+('a b','$VAR'(1))end\n", out.String())
+
+		out.Reset()
+		assert.NoError(t, i.QuerySolution(`write(user_output, foo), writeln(user_output, bar), print(user_output, 'B').`).Err())
+		assert.Equal(t, "foobar\n'B'", out.String())
+
+		assert.NoError(t, i.QuerySolution(`catch(write(foo, bar), error(existence_error(stream, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(nl(foo), error(existence_error(stream, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(writeln(foo, bar), error(existence_error(stream, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(print(foo, bar), error(existence_error(stream, foo), _), true).`).Err())
+	})
+
+	t.Run("module qualification operators", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		assert.NoError(t, i.QuerySolution(`current_op(200, xfy, :), current_op(200, xfx, @).`).Err())
+		assert.NoError(t, i.QuerySolution(`T = foo:bar(X), T =.. [:, foo, bar(Y)], Y == X.`).Err())
+		assert.NoError(t, i.QuerySolution(`T = m:g@c, T =.. [:, m, g@c].`).Err())
+		assert.NoError(t, i.QuerySolution(`writeq(foo:bar(x)), nl, writeq('@'(g, c)).`).Err())
+		assert.Equal(t, "foo:bar(x)\ng@c", out.String())
+	})
+
+	t.Run("file streams", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "foo.pl")
+
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`open(?, write, S), writeq(S, foo('a b')), write(S, '.'), nl(S), close(S).`, name).Err())
+		assert.NoError(t, i.QuerySolution(`open(?, append, S), write(S, 'bar.'), close(S).`, name).Err())
+
+		var s struct {
+			T1, T2, T3 TermString
+		}
+		assert.NoError(t, i.QuerySolution(`open(?, read, _, [alias(in), type(text), eof_action(eof_code)]), read(in, T1), read(in, T2), read(in, T3), close(in).`, name).Scan(&s))
+		assert.Equal(t, TermString("foo('a b')"), s.T1)
+		assert.Equal(t, TermString("bar"), s.T2)
+		assert.Equal(t, TermString("end_of_file"), s.T3)
+
+		assert.NoError(t, i.QuerySolution(`open(?, read, S), catch(write(S, foo), error(permission_error(output, stream, S), _), true), close(S).`, name).Err())
+		assert.NoError(t, i.QuerySolution(`catch(open(?, read, _), error(existence_error(source_sink, _), _), true).`, filepath.Join(t.TempDir(), "missing.pl")).Err())
+		assert.NoError(t, i.QuerySolution(`catch(read(in, _), error(existence_error(stream, in), _), true).`).Err())
+	})
+
+	t.Run("stream_property/2", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "foo.txt")
+
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`findall(A, stream_property(_, alias(A)), As), msort(As, [user_error, user_input, user_output]).`).Err())
+		assert.NoError(t, i.QuerySolution(`open(?, write, S, [alias(out), type(binary)]),
+			stream_property(S, mode(write)), stream_property(S, output), \+ stream_property(S, input),
+			stream_property(S, alias(out)), stream_property(S, type(binary)),
+			stream_property(S, position(0)), stream_property(S, end_of_stream(not)),
+			put_byte(out, 1), stream_property(S, position(1)),
+			close(S), \+ stream_property(_, alias(out)).`, name).Err())
+	})
+
+	t.Run("redirect the current input and output", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "foo.pl")
+
+		var out bytes.Buffer
+		i := New(strings.NewReader("from_user."), &out)
+		assert.NoError(t, i.QuerySolution(`current_output(U), open(?, write, S), set_output(S), write('foo.'), current_output(S), set_output(user_output), close(S), current_output(U).`, name).Err())
+		assert.NoError(t, i.QuerySolution(`open(?, read, S), set_input(S), read(foo), current_input(S), set_input(user_input), close(S), read(from_user), write(done).`, name).Err())
+		assert.Equal(t, "done", out.String())
+
+		assert.NoError(t, i.QuerySolution(`catch(set_output(foo), error(existence_error(stream, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(set_input(foo), error(existence_error(stream, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(set_output(user_input), error(permission_error(output, stream, user_input), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(set_input(user_output), error(permission_error(input, stream, user_output), _), true).`).Err())
+	})
+
+	t.Run("ground/1 on a cyclic term", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`X = f(X), ground(X).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`X = f(X, Y), ground(X).`).Err())
+	})
+
+	t.Run("character code arithmetic", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`X is 0'A + 1, X =:= 66, X == 66.`).Err())
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(double_quotes, codes).`).Err())
+		assert.NoError(t, i.QuerySolution(`X is "a", X == 97.`).Err())
+	})
+
+	t.Run("setof with chained existential quantifiers", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`
+pair(b, 1, x).
+pair(a, 2, y).
+pair(b, 3, z).
+`))
+		sol := i.QuerySolution(`setof(X, Y^Z^pair(X, Y, Z), L).`)
+		assert.NoError(t, sol.Err())
+
+		var s struct {
+			L []string
+		}
+		assert.NoError(t, sol.Scan(&s))
+		assert.Equal(t, []string{"a", "b"}, s.L)
+
+		// Without ^, the solutions are grouped by the free variables.
+		sols, err := i.Query(`setof(X, pair(X, Y, Z), L).`)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, sols.Close())
+		}()
+		var n int
+		for sols.Next() {
+			n++
+		}
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("read_term/2 from the current input", func(t *testing.T) {
+		i := New(strings.NewReader(`f(X, Y, X, _Z, _). `), nil)
+		sol := i.QuerySolution(`read_term(T, [variable_names(VNs), variables(Vs), singletons(Ss)]).`)
+		assert.NoError(t, sol.Err())
+
+		var s struct {
+			T   TermString
+			VNs []TermString
+			Vs  []TermString
+			Ss  []TermString
+		}
+		assert.NoError(t, sol.Scan(&s))
+		assert.Regexp(t, `\Af\(_\d+,_\d+,_\d+,_\d+,_\d+\)\z`, string(s.T))
+		assert.Len(t, s.VNs, 3)
+		assert.Len(t, s.Vs, 4)
+		assert.Len(t, s.Ss, 2)
+	})
+
+	t.Run("peek then read", func(t *testing.T) {
+		i := New(strings.NewReader(`abc foo(bar). baz.`), nil)
+
+		var s struct {
+			P, C1, C2 string
+		}
+		assert.NoError(t, i.QuerySolution(`peek_char(P), get_char(C1), get_char(C2).`).Scan(&s))
+		assert.Equal(t, "a", s.P)
+		assert.Equal(t, "a", s.C1)
+		assert.Equal(t, "b", s.C2)
+
+		var u struct {
+			P string
+			T TermString
+		}
+		assert.NoError(t, i.QuerySolution(`get_char(_), get_char(_), peek_char(P), read(T).`).Scan(&u))
+		assert.Equal(t, "f", u.P)
+		assert.Equal(t, TermString("foo(bar)"), u.T)
+
+		// The layout character following the end token is left in the stream.
+		assert.NoError(t, i.QuerySolution(`peek_char(' '), get_char(_), peek_char(b), read(T).`).Scan(&u))
+		assert.Equal(t, TermString("baz"), u.T)
+	})
+
+	t.Run("character input from the user input", func(t *testing.T) {
+		i := New(nil, nil)
+		i.SetUserInput(engine.NewInputTextStream(strings.NewReader("ab")))
+
+		var s struct {
+			P, C1, C3 string
+			C2        int
+		}
+		assert.NoError(t, i.QuerySolution(`peek_char(P), get_char(C1), get_code(C2), get_char(C3).`).Scan(&s))
+		assert.Equal(t, "a", s.P)
+		assert.Equal(t, "a", s.C1)
+		assert.Equal(t, int('b'), s.C2)
+		assert.Equal(t, "end_of_file", s.C3)
+
+		assert.NoError(t, i.QuerySolution(`catch(get_char(user_output, _), error(permission_error(input, stream, user_output), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(peek_char(user_output, _), error(permission_error(input, stream, user_output), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(get_code(user_output, _), error(permission_error(input, stream, user_output), _), true).`).Err())
+	})
+
+	t.Run("char_conversion affects subsequent parsing", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`char_conversion(x, y).`).Err())
+		assert.NoError(t, i.QuerySolution(`current_char_conversion(x, y).`).Err())
+
+		// The conversion doesn't apply while the flag is off.
+		assert.NoError(t, i.QuerySolution(`x \== y.`).Err())
+
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(char_conversion, on).`).Err())
+		assert.NoError(t, i.QuerySolution(`x == y.`).Err())
+
+		// Quoted tokens are intact.
+		assert.NoError(t, i.QuerySolution(`'x' \== y.`).Err())
+	})
+
+	t.Run("prolog flags affect subsequent queries", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(double_quotes, atom), current_prolog_flag(double_quotes, atom).`).Err())
+		assert.NoError(t, i.QuerySolution(`X = "ab", atom(X).`).Err())
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(double_quotes, chars).`).Err())
+		assert.NoError(t, i.QuerySolution(`"ab" = [a, b].`).Err())
+
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(unknown, fail), current_prolog_flag(unknown, fail).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`undefined_foo.`).Err())
+
+		assert.NoError(t, i.QuerySolution(`catch((set_prolog_flag(foo, bar), fail), error(domain_error(prolog_flag, foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch((set_prolog_flag(double_quotes, foo), fail), error(domain_error(flag_value, double_quotes+foo), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch((set_prolog_flag(bounded, false), fail), error(permission_error(modify, flag, bounded), _), true).`).Err())
+	})
+
+	t.Run("integer overflow", func(t *testing.T) {
+		// Integers are always bounded. There are no big integers to promote to and bounded can't be set to false.
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`current_prolog_flag(bounded, true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch((X is 3037000500 * 3037000500, fail), error(evaluation_error(int_overflow), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch((X is 9223372036854775807 + 1, fail), error(evaluation_error(int_overflow), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch((X is 1 << 63, fail), error(evaluation_error(int_overflow), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`X is 3037000499 * 3037000499, X =:= 9223372030926249001.`).Err())
+	})
+
+	t.Run("halt", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		var halted []int
+		i.OnHalt = func(code int) {
+			halted = append(halted, code)
+		}
+
+		err := i.QuerySolution(`write(a), catch(halt(3), _, true), write(b).`).Err()
+		code, ok := engine.ExitCode(err)
+		assert.True(t, ok)
+		assert.Equal(t, 3, code)
+		assert.Equal(t, []int{3}, halted)
+		assert.Equal(t, "a", out.String())
+
+		code, ok = engine.ExitCode(i.QuerySolution(`halt.`).Err())
+		assert.True(t, ok)
+		assert.Equal(t, 0, code)
+	})
+
+	t.Run("unterminated quotes need more input", func(t *testing.T) {
+		i := New(nil, nil)
+		for _, q := range []string{"X = 'abc", `X = "abc`, "X = a /* comment"} {
+			_, err := i.Query(q)
+			assert.Equal(t, io.EOF, err, q)
+		}
+
+		// A new line in quotes is allowed only after a backslash.
+		_, err := i.Query("X = 'abc\\\n")
+		assert.Equal(t, io.EOF, err)
+		sols, err := i.Query("X = 'abc\\\ndef'.")
+		assert.NoError(t, err)
+		assert.NoError(t, sols.Close())
+		_, err = i.Query("X = 'abc\ndef'.")
+		assert.Error(t, err)
+	})
+
+	t.Run("cancel runaway queries", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`loop :- loop.`))
+
+		for _, q := range []string{`repeat, fail.`, `loop.`} {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(10*time.Millisecond, cancel)
+			assert.Equal(t, context.Canceled, i.QuerySolutionContext(ctx, q).Err(), q)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, i.QuerySolutionContext(ctx, `loop.`).Err())
+	})
+
+	t.Run("inference limit", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`loop :- loop.`))
+
+		var s struct {
+			R string
+		}
+		assert.NoError(t, i.QuerySolution(`call_with_inference_limit(loop, 1000, R).`).Scan(&s))
+		assert.Equal(t, "inference_limit_exceeded", s.R)
+		assert.NoError(t, i.QuerySolution(`call_with_inference_limit(member(X, [a, b]), 1000, R).`).Scan(&s))
+		assert.Equal(t, "true", s.R)
+		assert.NoError(t, i.QuerySolution(`call_with_inference_limit(X = a, 1000, R).`).Scan(&s))
+		assert.Equal(t, "!", s.R)
+
+		var n struct {
+			N int
+		}
+		assert.NoError(t, i.QuerySolution(`call_with_inference_limit(X = a, 1000, _, N).`).Scan(&n))
+		assert.Equal(t, 999, n.N)
+
+		// Catching the error and trying again doesn't evade the limit.
+		assert.NoError(t, i.Exec(`evade :- catch(loop, _, true), evade.`))
+		assert.NoError(t, i.QuerySolution(`call_with_inference_limit(evade, 1000, R).`).Scan(&s))
+		assert.Equal(t, "inference_limit_exceeded", s.R)
+
+		i.InferenceLimit = 1000
+		assert.Error(t, i.QuerySolution(`loop.`).Err())
+		assert.Error(t, i.QuerySolution(`catch(loop, error(resource_error(inference_limit_exceeded), _), true).`).Err())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var e engine.Exception
+		assert.ErrorAs(t, i.QuerySolutionContext(ctx, `evade.`).Err(), &e)
+		assert.Contains(t, e.Error(), "resource_error(inference_limit_exceeded)")
+	})
+
+	t.Run("call with additional arguments", func(t *testing.T) {
+		i := New(nil, nil)
+
+		var s struct {
+			X int
+		}
+		assert.NoError(t, i.QuerySolution(`call(plus(1), 2, X).`).Scan(&s))
+		assert.Equal(t, 3, s.X)
+		assert.NoError(t, i.QuerySolution(`call(call, call(plus, 1), 2, X).`).Scan(&s))
+		assert.Equal(t, 3, s.X)
+
+		assert.NoError(t, i.QuerySolution(`catch(call(_, 1), error(instantiation_error, _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(call(1, a), error(type_error(callable, 1), _), true).`).Err())
+	})
+
+	t.Run("foldl", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`
+add(X, Y, Z) :- Z is X + Y.
+mul_add(X, Y, Z0, Z) :- Z is Z0 + X * Y.
+add3(X, Y, Z, S0, S) :- S is S0 + X + Y + Z.
+either(X, A, [X|A]).
+either(_, A, A).
+`))
+
+		var s struct {
+			Sum int
+		}
+		assert.NoError(t, i.QuerySolution(`foldl(add, [1, 2, 3], 0, Sum).`).Scan(&s))
+		assert.Equal(t, 6, s.Sum)
+		assert.NoError(t, i.QuerySolution(`foldl(mul_add, [1, 2, 3], [4, 5, 6], 0, Sum).`).Scan(&s))
+		assert.Equal(t, 32, s.Sum)
+		assert.NoError(t, i.QuerySolution(`foldl(add3, [1, 2], [3, 4], [5, 6], 0, Sum).`).Scan(&s))
+		assert.Equal(t, 21, s.Sum)
+
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`foldl(mul_add, [1, 2], [3], 0, _).`).Err())
+
+		sols, err := i.Query(`foldl(either, [a, b], [], L).`)
+		assert.NoError(t, err)
+		var ls []string
+		for sols.Next() {
+			var s struct {
+				L []string
+			}
+			assert.NoError(t, sols.Scan(&s))
+			ls = append(ls, strings.Join(s.L, ""))
+		}
+		assert.NoError(t, sols.Close())
+		assert.Equal(t, []string{"ba", "a", "b", ""}, ls)
+	})
+
+	t.Run("digit separators", func(t *testing.T) {
+		i := New(nil, nil)
+		_, err := i.Query(`X = 1_000.`)
+		assert.Error(t, err)
+
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(digit_separators, on).`).Err())
+		var s struct {
+			X int
+		}
+		assert.NoError(t, i.QuerySolution(`X = 1_000_000.`).Scan(&s))
+		assert.Equal(t, 1000000, s.X)
+	})
+
+	t.Run("occurs check", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`X = f(X).`).Err())
+
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(occurs_check, true).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`X = f(X).`).Err())
+
+		assert.NoError(t, i.QuerySolution(`set_prolog_flag(occurs_check, error).`).Err())
+		assert.Error(t, i.QuerySolution(`X = f(X).`).Err())
+		assert.NoError(t, i.QuerySolution(`X = f(Y).`).Err())
+	})
+
+	t.Run("soft cut", func(t *testing.T) {
+		i := New(nil, nil)
+
+		solutions := func(query string) []string {
+			sols, err := i.Query(query)
+			assert.NoError(t, err)
+			var ys []string
+			for sols.Next() {
+				var s struct {
+					Y string
+				}
+				assert.NoError(t, sols.Scan(&s))
+				ys = append(ys, s.Y)
+			}
+			assert.NoError(t, sols.Close())
+			return ys
+		}
+
+		assert.Equal(t, []string{"a"}, solutions(`(member(X, [a, b]) -> Y = X ; Y = c).`))
+		assert.Equal(t, []string{"a", "b"}, solutions(`(member(X, [a, b]) *-> Y = X ; Y = c).`))
+		assert.Equal(t, []string{"c"}, solutions(`(member(X, []) *-> Y = X ; Y = c).`))
+		assert.Equal(t, []string{"a", "b"}, solutions(`(member(X, [a, b]) *-> Y = X).`))
+		assert.Equal(t, []string{"a", "b", "d"}, solutions(`((member(X, [a, b]) *-> Y = X ; Y = c) ; Y = d).`))
+	})
+
+	t.Run("writeq control characters", func(t *testing.T) {
+		var out bytes.Buffer
+		i := New(nil, &out)
+		assert.NoError(t, i.QuerySolution(`atom_codes(A, [0'a, 10, 0'b, 0, 0'c, 9]), writeq(A).`).Err())
+		assert.Equal(t, `'a\nb\x0\c\t'`, out.String())
+
+		// The output reads back as the same atom.
+		assert.NoError(t, i.QuerySolution(fmt.Sprintf(`atom_codes(A, [0'a, 10, 0'b, 0, 0'c, 9]), A == %s.`, out.String())).Err())
+	})
+
+	t.Run("if-then-else", func(t *testing.T) {
+		i := New(nil, nil)
+
+		var s struct {
+			X, Y string
+		}
+		assert.NoError(t, i.QuerySolution(`(member(X, [a, b]) -> Y = then ; Y = else).`).Scan(&s))
+		assert.Equal(t, "a", s.X)
+		assert.Equal(t, "then", s.Y)
+		assert.NoError(t, i.QuerySolution(`(member(_, []) -> Y = then ; Y = else).`).Scan(&s))
+		assert.Equal(t, "else", s.Y)
+		assert.NoError(t, i.QuerySolution(`(member(X, [a, b]) -> Y = then).`).Scan(&s))
+		assert.Equal(t, "a", s.X)
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`(fail -> true).`).Err())
+
+		// The cut in the condition is local to the condition.
+		assert.NoError(t, i.QuerySolution(`((member(Z, [a, b]), !, Z = b) -> Y = then ; Y = else).`).Scan(&s))
+		assert.Equal(t, "else", s.Y)
+
+		// The choice points in the branches and the outside survive.
+		sols, err := i.Query(`member(X, [a, b]), (true -> member(Y, [c, d]) ; Y = e).`)
+		assert.NoError(t, err)
+		var xys []string
+		for sols.Next() {
+			assert.NoError(t, sols.Scan(&s))
+			xys = append(xys, s.X+s.Y)
+		}
+		assert.NoError(t, sols.Close())
+		assert.Equal(t, []string{"ac", "ad", "bc", "bd"}, xys)
+	})
+
+	t.Run("once and ignore", func(t *testing.T) {
+		i := New(nil, nil)
+
+		var s struct {
+			X, Y string
+		}
+		sols, err := i.Query(`member(X, [a, b]), once(member(Y, [c, d])).`)
+		assert.NoError(t, err)
+		var xys []string
+		for sols.Next() {
+			assert.NoError(t, sols.Scan(&s))
+			xys = append(xys, s.X+s.Y)
+		}
+		assert.NoError(t, sols.Close())
+		assert.Equal(t, []string{"ac", "bc"}, xys)
+
+		sols, err = i.Query(`member(X, [a, b]), ignore(member(Y, [c, d])).`)
+		assert.NoError(t, err)
+		xys = nil
+		for sols.Next() {
+			assert.NoError(t, sols.Scan(&s))
+			xys = append(xys, s.X+s.Y)
+		}
+		assert.NoError(t, sols.Close())
+		assert.Equal(t, []string{"ac", "bc"}, xys)
+
+		assert.NoError(t, i.QuerySolution(`ignore(fail).`).Err())
+		assert.NoError(t, i.QuerySolution(`ignore(X = a), X == a.`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`once(fail).`).Err())
+	})
+
+	t.Run("not", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`not(true).`).Err())
+		assert.NoError(t, i.QuerySolution(`not(fail).`).Err())
+		assert.NoError(t, i.QuerySolution(`\+ \+ X = a, var(X).`).Err())
+		assert.NoError(t, i.QuerySolution(`not(not(X = a)), var(X).`).Err())
+		assert.NoError(t, i.QuerySolution(`(member(X, [a, b]), \+ (!, X = a)), X == b.`).Err())
+	})
+
+	t.Run("subsumes", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`subsumes_term(f(X, Y), f(a, b)), var(X), var(Y).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`subsumes_term(f(a, b), f(X, Y)).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`subsumes_term(f(X, X), f(a, b)).`).Err())
+
+		var s struct{ X, Y string }
+		assert.NoError(t, i.QuerySolution(`subsumes(f(X, Y), f(a, b)).`).Scan(&s))
+		assert.Equal(t, "a", s.X)
+		assert.Equal(t, "b", s.Y)
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`subsumes(f(a, b), f(X, Y)).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`subsumes(f(X, X), f(a, b)).`).Err())
+	})
+
+	t.Run("retractall and abolish", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`:- dynamic(foo/1).
+foo(a).
+foo(b).
+foo(c).
+`))
+		assert.NoError(t, i.QuerySolution(`retractall(foo(b)), findall(X, foo(X), [a, c]).`).Err())
+		assert.NoError(t, i.QuerySolution(`retractall(bar(_)), \+bar(_).`).Err())
+		assert.NoError(t, i.QuerySolution(`abolish(foo/1), abolish(foo/1), abolish(baz/0).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(abolish(atom_length/2), error(permission_error(modify, static_procedure, atom_length/2), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(retractall(atom_length(_, _)), error(permission_error(modify, static_procedure, atom_length/2), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(abolish(foo), error(type_error(predicate_indicator, foo), _), true).`).Err())
+	})
+
+	t.Run("forall", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`forall(member(X, [1, 2, 3]), X > 0).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`forall(member(X, [1, 2, 3]), X > 1).`).Err())
+		assert.NoError(t, i.QuerySolution(`forall(fail, fail).`).Err())
+		assert.NoError(t, i.QuerySolution(`forall(member(X, [a, b]), (member(Y, [X, c]), !)), var(X), var(Y).`).Err())
+
+		sols, err := i.Query(`forall(member(X, [1, 2]), integer(X)).`)
+		assert.NoError(t, err)
+		assert.True(t, sols.Next())
+		assert.False(t, sols.Next())
+		assert.NoError(t, sols.Close())
+	})
+
+	t.Run("variant", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`f(X, Y) =@= f(A, B), var(X), var(A).`).Err())
+		assert.NoError(t, i.QuerySolution(`f(X, X) \=@= f(A, B).`).Err())
+		assert.NoError(t, i.QuerySolution(`f(a, [b]) =@= f(a, [b]).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`f(X, Y) \=@= f(A, B).`).Err())
+	})
+
+	t.Run("rename_term", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`rename_term(f(X, Y), T), rename_term(f(A, B), T), var(X), var(A).`).Err())
+		assert.Equal(t, ErrNoSolutions, i.QuerySolution(`rename_term(f(X, Y), T), rename_term(f(A, A), T).`).Err())
+		assert.NoError(t, i.QuerySolution(`catch(rename_term(f('$VAR'(0), X), _), error(type_error(variable, '$VAR'(0)), _), true).`).Err())
+
+		var s struct {
+			T TermString
+		}
+		assert.NoError(t, i.QuerySolution(`rename_term(g(X, a, Y, X), T).`).Scan(&s))
+		assert.Equal(t, TermString(`g('$VAR'(0),a,'$VAR'(1),'$VAR'(0))`), s.T)
+	})
+
+	t.Run("assert cyclic clause", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.Error(t, i.QuerySolution(`X = (true, X), assertz((foo :- X)).`).Err())
+		assert.Error(t, i.QuerySolution(`X = f(X), asserta(foo(X)).`).Err())
+		assert.NoError(t, i.QuerySolution(`\+ current_predicate(foo/0), \+ current_predicate(foo/1).`).Err())
+	})
+
+	t.Run("assert over builtin", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.QuerySolution(`catch(assertz(append(a, b, c)), error(permission_error(modify, static_procedure, append/3), _), true).`).Err())
+		assert.NoError(t, i.QuerySolution(`append([a], [b], [a, b]).`).Err())
+	})
+
+	t.Run("assert over dynamic", func(t *testing.T) {
+		i := New(nil, nil)
+		assert.NoError(t, i.Exec(`
+:- dynamic(foo/1).
+foo(a).
+`))
+		assert.NoError(t, i.QuerySolution(`assertz(foo(b)), findall(X, foo(X), [a, b]).`).Err())
+	})
 }
 
 func TestInterpreter_QuerySolution(t *testing.T) {
@@ -1236,6 +2008,51 @@ foo(c, d).
 	})
 }
 
+func TestInterpreter_QueryOnce(t *testing.T) {
+	var i Interpreter
+	assert.NoError(t, i.Exec(`
+foo(a, f(Z, Z)).
+foo(b, c).
+`))
+
+	t.Run("ok", func(t *testing.T) {
+		m, err := i.QueryOnce(`foo(X, Y).`)
+		assert.NoError(t, err)
+		assert.Len(t, m, 2)
+		assert.Equal(t, engine.NewAtom("a"), m["X"])
+
+		y, ok := m["Y"].(engine.Compound)
+		assert.True(t, ok)
+		assert.Equal(t, engine.NewAtom("f"), y.Functor())
+		assert.Equal(t, y.Arg(0), y.Arg(1))
+		_, ok = y.Arg(0).(engine.Variable)
+		assert.True(t, ok)
+	})
+
+	t.Run("no solutions", func(t *testing.T) {
+		m, err := i.QueryOnce(`foo(e, f).`)
+		assert.Equal(t, ErrNoSolutions, err)
+		assert.Nil(t, m)
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		err := errors.New("something went wrong")
+
+		i.Register0(engine.NewAtom("error"), func(_ *engine.VM, k engine.Cont, env *engine.Env) *engine.Promise {
+			return engine.Error(err)
+		})
+		m, e := i.QueryOnce(`error.`)
+		assert.Equal(t, err, e)
+		assert.Nil(t, m)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		m, err := i.QueryOnce(``)
+		assert.Error(t, err)
+		assert.Nil(t, m)
+	})
+}
+
 func ExampleInterpreter_Exec_placeholders() {
 	p := New(nil, os.Stdout)
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/ichiban/prolog/engine"
 )
@@ -49,7 +50,14 @@ func (s *Solutions) Next() bool {
 	return ok
 }
 
+// ProofTree returns the proof tree of the current solution which shows the clauses used to prove the goals.
+// It returns nil unless VM.CaptureProofTree is enabled.
+func (s *Solutions) ProofTree() *engine.ProofNode {
+	return s.env.ProofTree()
+}
+
 // Scan copies the variable values of the current solution into the specified struct/map.
+// A string field accepts an atom, a list of characters, or a list of character codes.
 func (s *Solutions) Scan(dest interface{}) error {
 	o := reflect.ValueOf(dest)
 	for o.Kind() == reflect.Ptr {
@@ -171,6 +179,31 @@ func convertAssignString(d *string, t engine.Term, env *engine.Env) error {
 	case engine.Atom:
 		*d = t.String()
 		return nil
+	case engine.Compound: // A list of characters or character codes.
+		var sb strings.Builder
+		iter := engine.ListIterator{List: t, Env: env}
+		for iter.Next() {
+			switch e := env.Resolve(iter.Current()).(type) {
+			case engine.Atom:
+				r := []rune(e.String())
+				if len(r) != 1 {
+					return errConversion
+				}
+				_, _ = sb.WriteRune(r[0])
+			case engine.Integer:
+				if e < 0 || e > unicode.MaxRune {
+					return errConversion
+				}
+				_, _ = sb.WriteRune(rune(e))
+			default:
+				return errConversion
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return errConversion
+		}
+		*d = sb.String()
+		return nil
 	default:
 		return errConversion
 	}
@@ -273,6 +306,98 @@ func convertAssignSlice(d interface{}, vm *engine.VM, t engine.Term, env *engine
 	return nil
 }
 
+// WriteTermOptions specify how FormatBindings writes the values. They correspond to the write_term/2 options.
+type WriteTermOptions struct {
+	Quoted     bool
+	IgnoreOps  bool
+	NumberVars bool
+	MaxDepth   int // 0 means no limit.
+}
+
+func (o WriteTermOptions) terms() []engine.Term {
+	var (
+		atomTrue  = engine.NewAtom("true")
+		atomFalse = engine.NewAtom("false")
+	)
+	boolean := func(b bool) engine.Term {
+		if b {
+			return atomTrue
+		}
+		return atomFalse
+	}
+	depth := o.MaxDepth
+	if depth < 0 {
+		depth = 0
+	}
+	return []engine.Term{
+		engine.NewAtom("quoted").Apply(boolean(o.Quoted)),
+		engine.NewAtom("ignore_ops").Apply(boolean(o.IgnoreOps)),
+		engine.NewAtom("numbervars").Apply(boolean(o.NumberVars)),
+		engine.NewAtom("max_depth").Apply(engine.Integer(depth)),
+	}
+}
+
+// FormatBindings renders the bindings of the current solution as `X = value, Y = value` like a top level does after
+// each answer. Anonymous variables and unbound variables are omitted.
+// If a value fails to be written, it is rendered as `...` as if it were beyond the max depth.
+func (s *Solutions) FormatBindings(opts WriteTermOptions) string {
+	names := make([]engine.Term, 0, len(s.vars))
+	firsts := map[engine.Variable]engine.Atom{} // The first name of each unbound variable.
+	for _, v := range s.vars {
+		names = append(names, engine.NewAtom("=").Apply(v.Name, v.Variable))
+		if t, ok := s.env.Resolve(v.Variable).(engine.Variable); ok {
+			if _, ok := firsts[t]; !ok {
+				firsts[t] = v.Name
+			}
+		}
+	}
+	options := engine.List(append(opts.terms(), engine.NewAtom("variable_names").Apply(engine.List(names...)))...)
+
+	var sb strings.Builder
+	for _, v := range s.vars {
+		if strings.HasPrefix(v.Name.String(), "_") {
+			continue
+		}
+		if t, ok := s.env.Resolve(v.Variable).(engine.Variable); ok && firsts[t] == v.Name {
+			continue
+		}
+		if sb.Len() > 0 {
+			_, _ = sb.WriteString(", ")
+		}
+		_, _ = fmt.Fprintf(&sb, "%s = ", v.Name)
+		var buf strings.Builder
+		if _, err := engine.WriteTerm(s.vm, engine.NewOutputTextStream(&buf), v.Variable, options, engine.Success, s.env).Force(context.Background()); err != nil {
+			buf.Reset()
+			_, _ = buf.WriteString("...")
+		}
+		_, _ = sb.WriteString(buf.String())
+	}
+	return sb.String()
+}
+
+// bindings returns a copy of the variable values of the current solution keyed by the variable names.
+// The values are copied altogether so that they keep sharing variables among them.
+func (s *Solutions) bindings() (map[string]engine.Term, error) {
+	vs := make([]engine.Term, len(s.vars))
+	for i, v := range s.vars {
+		vs[i] = v.Variable
+	}
+	out := engine.NewVariable()
+	var values engine.Compound
+	if _, err := engine.CopyTerm(s.vm, engine.NewAtom("bindings").Apply(vs...), out, func(env *engine.Env) *engine.Promise {
+		values, _ = env.Resolve(out).(engine.Compound)
+		return engine.Bool(true)
+	}, s.env).Force(context.Background()); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]engine.Term, len(s.vars))
+	for i, v := range s.vars {
+		m[v.Name.String()] = values.Arg(i)
+	}
+	return m, nil
+}
+
 // Err returns the error if exists.
 func (s *Solutions) Err() error {
 	return s.err
@@ -107,6 +107,18 @@ func TestSolutions_Scan(t *testing.T) {
 		{title: "struct: string, non-atom", sols: sols(map[string]engine.Term{
 			"X": engine.Integer(1),
 		}), dest: &struct{ X string }{}, err: errConversion},
+		{title: "struct: string, characters", sols: sols(map[string]engine.Term{
+			"X": engine.CharList("abc"),
+		}), dest: &struct{ X string }{}, result: &struct{ X string }{X: "abc"}},
+		{title: "struct: string, character codes", sols: sols(map[string]engine.Term{
+			"X": engine.CodeList("abc"),
+		}), dest: &struct{ X string }{}, result: &struct{ X string }{X: "abc"}},
+		{title: "struct: string, list of atoms", sols: sols(map[string]engine.Term{
+			"X": engine.List(engine.NewAtom("a"), engine.NewAtom("bc")),
+		}), dest: &struct{ X string }{}, err: errConversion},
+		{title: "struct: string, partial list", sols: sols(map[string]engine.Term{
+			"X": engine.PartialList(engine.NewVariable(), engine.NewAtom("a")),
+		}), dest: &struct{ X string }{}, err: errConversion},
 
 		{title: "struct: int, integer", sols: sols(map[string]engine.Term{
 			"X": engine.Integer(1),
@@ -212,6 +224,75 @@ func TestSolutions_Scan(t *testing.T) {
 	}
 }
 
+func TestSolutions_FormatBindings(t *testing.T) {
+	p := New(nil, nil)
+
+	sols, err := p.Query(`X = 'hello world', Y = f(Z, [1, 2]), W = V, _A = 1.`)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sols.Close())
+	}()
+
+	assert.True(t, sols.Next())
+	assert.Equal(t, `X = 'hello world', Y = f(Z,[1,2]), V = W`, sols.FormatBindings(WriteTermOptions{Quoted: true}))
+	assert.Equal(t, `X = hello world, Y = f(Z,[1,2]), V = W`, sols.FormatBindings(WriteTermOptions{}))
+	assert.Equal(t, `X = hello world, Y = f(Z,[1|...]), V = W`, sols.FormatBindings(WriteTermOptions{MaxDepth: 2}))
+}
+
+func TestSolutions_ProofTree(t *testing.T) {
+	p := New(nil, nil)
+	p.CaptureProofTree = true
+	assert.NoError(t, p.Exec(`
+grandparent(X, Z) :- parent(X, Y), parent(Y, Z).
+parent(a, b).
+parent(b, c).
+parent(b, d).
+`))
+
+	var (
+		grandparent = engine.NewAtom("grandparent")
+		parent      = engine.NewAtom("parent")
+		a, b, c, d  = engine.NewAtom("a"), engine.NewAtom("b"), engine.NewAtom("c"), engine.NewAtom("d")
+	)
+
+	sols, err := p.Query(`grandparent(a, Z).`)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sols.Close())
+	}()
+
+	for _, z := range []engine.Term{c, d} {
+		assert.True(t, sols.Next())
+
+		root := sols.ProofTree()
+		assert.Equal(t, grandparent.Apply(a, z), root.Goal)
+		assert.Nil(t, root.Clause)
+		assert.Len(t, root.Children, 1)
+
+		n := root.Children[0]
+		assert.Equal(t, grandparent.Apply(a, z), n.Goal)
+		assert.Equal(t, engine.NewAtom(":-"), n.Clause.(engine.Compound).Functor())
+		assert.Equal(t, []*engine.ProofNode{
+			{Goal: parent.Apply(a, b), Clause: parent.Apply(a, b)},
+			{Goal: parent.Apply(b, z), Clause: parent.Apply(b, z)},
+		}, n.Children)
+	}
+
+	assert.False(t, sols.Next())
+}
+
+func TestSolutions_ProofTree_disabled(t *testing.T) {
+	p := New(nil, nil)
+	sols, err := p.Query(`true.`)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sols.Close())
+	}()
+
+	assert.True(t, sols.Next())
+	assert.Nil(t, sols.ProofTree())
+}
+
 func TestSolutions_Err(t *testing.T) {
 	err := errors.New("ng")
 	sols := Solutions{err: err}